@@ -2,11 +2,14 @@ package main
 
 import (
 	"log"
+	"os"
 	"warehouse-backend/internal/db"
 	"warehouse-backend/internal/handler"
 	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/model"
 	"warehouse-backend/internal/repo"
 	"warehouse-backend/internal/service"
+	"warehouse-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,33 +29,160 @@ func main() {
 	})
 	database := db.Connect()
 	db.AutoMigrate(database)
+
+	if err := utils.LoadJWTKeys(); err != nil {
+		log.Fatal("❌ Failed to load JWT keys: ", err)
+	}
+	if err := utils.LoadBusinessLocation(); err != nil {
+		log.Fatal("❌ ", err)
+	}
+	if err := repo.LoadPricingConfig(); err != nil {
+		log.Fatal("❌ ", err)
+	}
 	r.Static("/uploads", "./uploads")
 	itemHandler := handler.NewItemHandler(database)
+	vehicleHandler := handler.NewVehicleHandler(database)
+	analogHandler := handler.NewAnalogHandler(database)
+	reportHandler := handler.NewReportHandler(database)
+	promotionHandler := handler.NewPromotionHandler(database)
+	customerHandler := handler.NewCustomerHandler(database)
+	purchaseHandler := handler.NewPurchaseHandler(database)
+	warehouseHandler := handler.NewWarehouseHandler(database)
 
 	userRepo := repo.NewUserRepo(database)
 	userService := service.NewUserService(userRepo)
 	authHandler := handler.NewAuthHandler(userService)
 
+	// Первый администратор из окружения — только если пользователей ещё нет
+	if username, password := os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD"); username != "" && password != "" {
+		created, err := userService.BootstrapAdmin(username, password)
+		if err != nil {
+			log.Fatal("❌ Failed to create admin: ", err)
+		}
+		if created {
+			log.Println("✅ Admin user created:", username)
+		}
+	}
+
 	jwtService := service.NewJWTService()
 
+	userHandler := handler.NewUserHandler(userService)
+
 	api := r.Group("/api")
 	{
 
 		api.POST("/register", authHandler.Register)
 		api.POST("/login", authHandler.Login)
+		api.POST("/refresh", authHandler.Refresh)
+		api.POST("/logout", authHandler.Logout)
 
 		// Protected
 		protected := api.Group("/")
 		protected.Use(middleware.AuthMiddleware(jwtService))
 		{
+			// Роли: кто может продавать и кто может менять каталог и остатки
+			sellers := middleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleCashier)
+			managers := middleware.RequireRole(model.RoleAdmin, model.RoleManager)
+			admins := middleware.RequireRole(model.RoleAdmin)
+
 			protected.GET("/items", itemHandler.GetItems)
-			protected.POST("/items", itemHandler.AddItem)
-			protected.PATCH("/items/:id", itemHandler.UpdateItem)
+			protected.GET("/items/search", itemHandler.SearchItems)
+			protected.GET("/items/export", itemHandler.ExportItems)
+			protected.POST("/items", managers, itemHandler.AddItem)
+			protected.POST("/items/import", managers, itemHandler.ImportItems)
+			protected.PATCH("/items/:id", managers, itemHandler.UpdateItem)
+			protected.GET("/items/:id/movements", itemHandler.GetStockMovements)
+			protected.POST("/items/:id/movements", managers, itemHandler.AddStockMovement)
+			protected.GET("/stock/reconcile", managers, itemHandler.ReconcileStock)
+			protected.GET("/items/:id/stock", itemHandler.GetItemStock)
+			protected.GET("/items/low-stock", itemHandler.GetLowStock)
+			protected.GET("/stock/reorder", managers, itemHandler.GetSuggestedPurchases)
+			protected.GET("/stock/alerts", itemHandler.GetStockAlerts)
+			protected.POST("/stock/alerts/:id/ack", managers, itemHandler.AcknowledgeStockAlert)
 
-			protected.POST("/sale", itemHandler.MakeSale)
+			protected.GET("/warehouses", warehouseHandler.ListWarehouses)
+			protected.POST("/warehouses", managers, warehouseHandler.CreateWarehouse)
+			protected.PATCH("/warehouses/:id", managers, warehouseHandler.UpdateWarehouse)
+			protected.POST("/warehouses/:id/locations", managers, warehouseHandler.CreateLocation)
+			protected.PATCH("/locations/:id", managers, warehouseHandler.UpdateLocation)
+			protected.GET("/locations/:id/stock", warehouseHandler.GetLocationStock)
+
+			protected.GET("/transfers", itemHandler.ListTransfers)
+			protected.POST("/transfers", managers, itemHandler.CreateTransfer)
+			protected.GET("/transfers/:id", itemHandler.GetTransfer)
+			protected.PATCH("/transfers/:id", managers, itemHandler.UpdateTransfer)
+			protected.POST("/transfers/:id/ship", managers, itemHandler.ShipTransfer)
+			protected.POST("/transfers/:id/receive", managers, itemHandler.ReceiveTransfer)
+			protected.POST("/transfers/:id/cancel", managers, itemHandler.CancelTransfer)
+
+			protected.GET("/stocktakes", itemHandler.ListStocktakes)
+			protected.POST("/stocktakes", managers, itemHandler.CreateStocktake)
+			protected.GET("/stocktakes/:id", itemHandler.GetStocktake)
+			protected.POST("/stocktakes/:id/counts", sellers, itemHandler.RecordCounts)
+			protected.GET("/stocktakes/:id/variance", managers, itemHandler.GetStocktakeVariance)
+			protected.POST("/stocktakes/:id/approve", managers, itemHandler.ApproveStocktake)
+			protected.POST("/stocktakes/:id/cancel", managers, itemHandler.CancelStocktake)
+			protected.GET("/items/:id/vehicles", vehicleHandler.GetItemVehicles)
+			protected.POST("/items/:id/vehicles", managers, vehicleHandler.AddItemVehicles)
+			protected.DELETE("/items/:id/vehicles/:vehicleId", managers, vehicleHandler.RemoveItemVehicle)
+
+			protected.GET("/vehicles", vehicleHandler.ListVehicles)
+			protected.POST("/vehicles", managers, vehicleHandler.CreateVehicle)
+			protected.PATCH("/vehicles/:id", managers, vehicleHandler.UpdateVehicle)
+			protected.GET("/vehicles/parts", vehicleHandler.FindParts)
+
+			protected.GET("/analogs", analogHandler.FindAnalogs)
+			protected.GET("/cross-references", analogHandler.ListCrossReferences)
+			protected.POST("/cross-references", managers, analogHandler.CreateCrossReference)
+			protected.DELETE("/cross-references/:id", managers, analogHandler.DeleteCrossReference)
+			protected.POST("/cross-references/import", managers, analogHandler.ImportCrossReferences)
+
+			protected.POST("/sale", sellers, itemHandler.MakeSale)
+			protected.POST("/orders", sellers, itemHandler.CreateOrder)
+			protected.GET("/orders", itemHandler.GetOrders)
+			protected.GET("/orders/:id", itemHandler.GetOrder)
 			protected.GET("/sales/today", itemHandler.GetTodaySales)
 			protected.GET("/sales/top5", itemHandler.GetTop5BestSellers)
+			protected.GET("/sales/top", reportHandler.TopBestSellers)
 			protected.GET("/sales", itemHandler.GetSales)
+			protected.GET("/sales/export", managers, itemHandler.ExportSales)
+			protected.POST("/sales/:id/return", sellers, itemHandler.ReturnSale)
+			protected.GET("/sales/:id/returns", itemHandler.GetSaleReturns)
+
+			protected.GET("/customers", customerHandler.SearchCustomers)
+			protected.POST("/customers", sellers, customerHandler.CreateCustomer)
+			protected.GET("/customers/:id", customerHandler.GetCustomer)
+			protected.PATCH("/customers/:id", managers, customerHandler.UpdateCustomer)
+			protected.GET("/customers/:id/history", customerHandler.GetCustomerHistory)
+			protected.GET("/customers/:id/balance", customerHandler.GetCustomerBalance)
+			protected.GET("/customers/:id/payments", customerHandler.GetPayments)
+			protected.POST("/customers/:id/payments", sellers, customerHandler.RecordPayment)
+
+			protected.GET("/suppliers", managers, purchaseHandler.ListSuppliers)
+			protected.POST("/suppliers", managers, purchaseHandler.CreateSupplier)
+			protected.PATCH("/suppliers/:id", managers, purchaseHandler.UpdateSupplier)
+			protected.GET("/purchase-orders", managers, purchaseHandler.ListPurchaseOrders)
+			protected.POST("/purchase-orders", managers, purchaseHandler.CreatePurchaseOrder)
+			protected.GET("/purchase-orders/:id", managers, purchaseHandler.GetPurchaseOrder)
+			protected.PATCH("/purchase-orders/:id", managers, purchaseHandler.UpdatePurchaseOrder)
+			protected.POST("/purchase-orders/:id/send", managers, purchaseHandler.SendPurchaseOrder)
+			protected.POST("/purchase-orders/:id/cancel", managers, purchaseHandler.CancelPurchaseOrder)
+			protected.POST("/purchase-orders/:id/receive", managers, purchaseHandler.ReceivePurchaseOrder)
+
+			protected.GET("/promotions", promotionHandler.ListPromotions)
+			protected.POST("/promotions", managers, promotionHandler.CreatePromotion)
+			protected.PATCH("/promotions/:id", managers, promotionHandler.UpdatePromotion)
+
+			protected.GET("/reports/sales", managers, reportHandler.SalesReport)
+			protected.GET("/reports/debts", managers, customerHandler.DebtAging)
+
+			protected.GET("/users", admins, userHandler.ListUsers)
+			protected.POST("/users", admins, userHandler.CreateUser)
+			protected.PATCH("/users/:id/role", admins, userHandler.SetRole)
+			protected.PATCH("/users/:id/status", admins, userHandler.SetStatus)
+			protected.POST("/users/me/password", userHandler.ChangePassword)
+			protected.POST("/invites", admins, userHandler.CreateInvite)
+			protected.GET("/invites", admins, userHandler.ListInvites)
 		}
 	}
 
@@ -0,0 +1,52 @@
+// createadmin создаёт первого администратора в пустой базе:
+//
+//	go run ./cmd/createadmin -username admin -password secret
+//
+// Если пользователи уже есть, но активного администратора нет (например, после
+// перехода на роли), можно один раз повысить существующего пользователя — пароль
+// при этом не меняется:
+//
+//	go run ./cmd/createadmin -promote -username ivan
+package main
+
+import (
+	"flag"
+	"log"
+	"warehouse-backend/internal/db"
+	"warehouse-backend/internal/repo"
+	"warehouse-backend/internal/service"
+)
+
+func main() {
+	username := flag.String("username", "", "admin username")
+	password := flag.String("password", "", "admin password")
+	promote := flag.Bool("promote", false, "promote an existing user when there is no active admin")
+	flag.Parse()
+
+	if *username == "" || (*password == "" && !*promote) {
+		flag.Usage()
+		log.Fatal("❌ -username and -password (or -promote) are required")
+	}
+
+	database := db.Connect()
+	db.AutoMigrate(database)
+
+	userService := service.NewUserService(repo.NewUserRepo(database))
+
+	if *promote {
+		if err := userService.PromoteFirstAdmin(*username); err != nil {
+			log.Fatal("❌ Failed to promote admin: ", err)
+		}
+		log.Println("✅ User promoted to admin:", *username)
+		return
+	}
+
+	created, err := userService.BootstrapAdmin(*username, *password)
+	if err != nil {
+		log.Fatal("❌ Failed to create admin: ", err)
+	}
+	if !created {
+		log.Fatal("❌ Users already exist, admin was not created (use -promote to promote an existing user)")
+	}
+	log.Println("✅ Admin user created:", *username)
+}
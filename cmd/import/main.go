@@ -0,0 +1,72 @@
+// import загружает прайс из CSV или XLSX в базу:
+//
+//	go run ./cmd/import -file price.xlsx -dry-run
+//	go run ./cmd/import -file price.csv -mapping '{"Артикул":"partNumber","Фирма":"brand"}'
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"warehouse-backend/internal/db"
+	"warehouse-backend/internal/repo"
+	"warehouse-backend/internal/service"
+)
+
+func main() {
+	path := flag.String("file", "", "CSV or XLSX file")
+	dryRun := flag.Bool("dry-run", false, "only report what would change")
+	rawMapping := flag.String("mapping", "", `column mapping as JSON, e.g. {"Артикул":"partNumber"}`)
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		log.Fatal("❌ -file is required")
+	}
+
+	var mapping map[string]string
+	if *rawMapping != "" {
+		if err := json.Unmarshal([]byte(*rawMapping), &mapping); err != nil {
+			log.Fatal("❌ Invalid -mapping: ", err)
+		}
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	defer file.Close()
+
+	sheet, readErrs, err := service.ReadSheet(*path, file)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	rows, parseErrs, err := service.ParseItemSheet(sheet, mapping)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	database := db.Connect()
+	db.AutoMigrate(database)
+
+	report, err := repo.NewItemRepository(database).ImportItems(rows, *dryRun, nil)
+	if err != nil {
+		log.Fatal("❌ Import failed: ", err)
+	}
+	report.Errors = append(append(readErrs, parseErrs...), report.Errors...)
+
+	for _, e := range report.Errors {
+		log.Printf("⚠️  row %d: %s", e.Row, e.Error)
+	}
+	if *dryRun {
+		for _, r := range report.Rows {
+			if r.Action != repo.ImportUnchanged {
+				log.Printf("row %d: %s %s %s %v", r.Row, r.Action, r.Brand, r.PartNumber, r.Changes)
+			}
+		}
+		log.Println("ℹ️  Dry run, nothing was saved")
+	}
+	log.Printf("✅ created: %d, updated: %d, unchanged: %d, errors: %d",
+		report.Created, report.Updated, report.Unchanged, len(report.Errors))
+}
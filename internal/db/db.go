@@ -30,14 +30,162 @@ func Connect() *gorm.DB {
 }
 
 func AutoMigrate(db *gorm.DB) {
+	// цена и себестоимость в продажах появились позже — старые продажи заполним один раз
+	backfillSaleSnapshots := !db.Migrator().HasColumn(&model.Sale{}, "unit_cost")
+	// справочник покупателей появился позже — старые продажи привяжем к нему один раз
+	backfillCustomers := !db.Migrator().HasColumn(&model.Sale{}, "customer_id")
+	// оплата чеков появилась позже — старые чеки считаем оплаченными
+	backfillPayments := !db.Migrator().HasColumn(&model.Order{}, "paid_amount")
+	// места хранения появились позже — текущие остатки кладём на основное место
+	seedLocations := !db.Migrator().HasTable(&model.ItemStock{})
+	// себестоимость раньше хранилась в оптовой цене — переносим её один раз
+	backfillLastCost := !db.Migrator().HasColumn(&model.Item{}, "last_cost")
+
 	err := db.AutoMigrate(
 		&model.Item{},
 		&model.ItemImage{},
+		&model.Order{},
 		&model.Sale{},
+		&model.SaleReturn{},
+		&model.Promotion{},
+		&model.Customer{},
+		&model.Payment{},
 		&model.User{},
+		&model.Invite{},
+		&model.RefreshToken{},
+		&model.StockMovement{},
+		&model.Vehicle{},
+		&model.CrossReference{},
+		&model.Supplier{},
+		&model.PurchaseOrder{},
+		&model.PurchaseOrderLine{},
+		&model.Warehouse{},
+		&model.Location{},
+		&model.ItemStock{},
+		&model.Transfer{},
+		&model.TransferLine{},
+		&model.Stocktake{},
+		&model.StocktakeLine{},
+		&model.StockAlert{},
 	)
 	if err != nil {
 		log.Fatal("❌ Migration error: ", err)
 	}
+
+	if backfillLastCost {
+		if err := db.Exec(`UPDATE items SET last_cost = wholesale_price`).Error; err != nil {
+			log.Fatal("❌ Migration error: ", err)
+		}
+	}
+
+	if backfillSaleSnapshots {
+		// себестоимость старых продаж неизвестна — берём текущую оптовую цену
+		err = db.Exec(`
+			UPDATE sales SET
+				unit_price = CASE WHEN sales.quantity > 0 THEN sales.total_price / sales.quantity ELSE 0 END,
+				unit_cost = items.wholesale_price
+			FROM items
+			WHERE sales.item_id = items.id`).Error
+		if err != nil {
+			log.Fatal("❌ Migration error: ", err)
+		}
+	}
+
+	if backfillCustomers {
+		// одинаковые с точностью до регистра и пробелов имена — один покупатель
+		nameKey := model.CustomerNameKeySQL("customer")
+		stmts := []string{
+			`INSERT INTO customers (name, name_key, phone, type, price_tier, notes, created_at)
+			SELECT MIN(trim(customer)), ` + nameKey + `, '', 'retail', 'retail', '', MIN(sold_at)
+			FROM sales
+			WHERE trim(customer) <> ''
+			GROUP BY ` + nameKey,
+			`UPDATE sales SET customer_id = customers.id
+			FROM customers
+			WHERE customers.name_key = ` + model.CustomerNameKeySQL("sales.customer"),
+			`UPDATE orders SET customer_id = customers.id
+			FROM customers
+			WHERE customers.name_key = ` + model.CustomerNameKeySQL("orders.customer"),
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				log.Fatal("❌ Migration error: ", err)
+			}
+		}
+	}
+
+	if backfillPayments {
+		err = db.Exec(`
+			UPDATE orders SET
+				refunded_amount = r.refunded,
+				paid_amount = orders.total_price - r.refunded,
+				payment_status = ?
+			FROM (SELECT order_id, SUM(refunded_amount) AS refunded FROM sales GROUP BY order_id) r
+			WHERE r.order_id = orders.id`, model.PaymentPaid).Error
+		if err != nil {
+			log.Fatal("❌ Migration error: ", err)
+		}
+	}
+
+	// Товарам без журнала записываем текущий остаток как начальный,
+	// чтобы сумма движений сходилась с items.stock
+	err = db.Exec(`
+		INSERT INTO stock_movements (item_id, type, quantity, reason, created_at)
+		SELECT id, ?, stock, 'начальный остаток', NOW()
+		FROM items
+		WHERE stock <> 0
+		  AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.item_id = items.id)`,
+		model.MovementAdjustment).Error
+	if err != nil {
+		log.Fatal("❌ Migration error: ", err)
+	}
+	// Основной склад с основным местом нужен всегда: туда идёт всё, где место не указано
+	var warehouses int64
+	if err := db.Model(&model.Warehouse{}).Count(&warehouses).Error; err != nil {
+		log.Fatal("❌ Migration error: ", err)
+	}
+	if warehouses == 0 {
+		err = db.Create(&model.Warehouse{
+			Name:      "Основной склад",
+			IsDefault: true,
+			Locations: []model.Location{{Code: "MAIN", Name: "Основное место", IsDefault: true}},
+		}).Error
+		if err != nil {
+			log.Fatal("❌ Migration error: ", err)
+		}
+	}
+
+	if seedLocations {
+		defaultLocation := `(SELECT locations.id FROM locations
+			JOIN warehouses ON warehouses.id = locations.warehouse_id
+			WHERE warehouses.is_default AND locations.is_default
+			ORDER BY locations.id LIMIT 1)`
+		stmts := []string{
+			`INSERT INTO item_stocks (item_id, location_id, quantity)
+			SELECT id, ` + defaultLocation + `, stock FROM items WHERE stock <> 0`,
+			`UPDATE stock_movements SET location_id = ` + defaultLocation + ` WHERE location_id IS NULL`,
+			`UPDATE sales SET location_id = ` + defaultLocation + ` WHERE location_id IS NULL`,
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				log.Fatal("❌ Migration error: ", err)
+			}
+		}
+	}
+
+	// Поиск по товарам: триграммы для нечёткого совпадения и частичных номеров,
+	// полнотекстовый индекс по названию, бренду и модели
+	searchIndexes := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_items_part_number_norm ON items USING gin ((` + model.ItemPartNumberNormSQL + `) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_items_search_text_trgm ON items USING gin ((` + model.ItemSearchTextSQL + `) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_items_search_text_fts ON items USING gin (to_tsvector('simple', ` + model.ItemSearchTextSQL + `))`,
+	}
+	for _, stmt := range searchIndexes {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Fatal("❌ Migration error: ", err)
+		}
+	}
+
 	log.Println("✅ Database migrated")
 }
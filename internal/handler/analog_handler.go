@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+	"warehouse-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AnalogHandler struct {
+	Repo *repo.AnalogRepository
+}
+
+func NewAnalogHandler(db *gorm.DB) *AnalogHandler {
+	return &AnalogHandler{
+		Repo: repo.NewAnalogRepository(db),
+	}
+}
+
+// FindAnalogs — все товары в наличии, взаимозаменяемые с номером:
+// ?number=&brand=, brand необязателен; &inStock=false чтобы показать и закончившиеся
+func (h *AnalogHandler) FindAnalogs(c *gin.Context) {
+	number := c.Query("number")
+	if number == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите номер детали"})
+		return
+	}
+
+	items, err := h.Repo.FindAnalogItems(c.Query("brand"), number, c.Query("inStock") != "false")
+	if errors.Is(err, repo.ErrEmptyPartNumber) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите номер детали"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось найти аналоги"})
+		return
+	}
+
+	hideWholesale(c, items)
+	c.JSON(http.StatusOK, items)
+}
+
+// ListCrossReferences — прямые связи номера: ?number=
+func (h *AnalogHandler) ListCrossReferences(c *gin.Context) {
+	refs, err := h.Repo.ListCrossReferences(c.Query("number"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить кроссы"})
+		return
+	}
+	c.JSON(http.StatusOK, refs)
+}
+
+func (h *AnalogHandler) CreateCrossReference(c *gin.Context) {
+	var ref model.CrossReference
+	if err := c.ShouldBindJSON(&ref); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.Repo.CreateCrossReference(&ref); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ref)
+}
+
+func (h *AnalogHandler) DeleteCrossReference(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	if err := h.Repo.DeleteCrossReference(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ImportCrossReferences загружает кроссы из CSV или XLSX (поле формы file) с колонками
+// brand, partNumber, analogBrand, analogPartNumber и необязательной source
+func (h *AnalogHandler) ImportCrossReferences(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл не передан"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось открыть файл"})
+		return
+	}
+	defer file.Close()
+
+	rows, errs, err := service.ReadSheet(fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Пустой файл"})
+		return
+	}
+
+	columns := service.SheetColumns(rows[0])
+	for _, required := range []string{"partNumber", "analogPartNumber"} {
+		if _, ok := columns[strings.ToLower(required)]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Нет колонки " + required})
+			return
+		}
+	}
+
+	refs := make([]model.CrossReference, 0, len(rows)-1)
+	rowNums := make([]int, 0, len(rows)-1)
+	for n, record := range rows[1:] {
+		if record == nil {
+			continue // строку не удалось разобрать, ошибка уже в errs
+		}
+		refs = append(refs, model.CrossReference{
+			Brand:            service.SheetField(record, columns, "brand"),
+			PartNumber:       service.SheetField(record, columns, "partNumber"),
+			AnalogBrand:      service.SheetField(record, columns, "analogBrand"),
+			AnalogPartNumber: service.SheetField(record, columns, "analogPartNumber"),
+			Source:           service.SheetField(record, columns, "source"),
+		})
+		rowNums = append(rowNums, n+2) // строки файла с 1, плюс заголовок
+	}
+
+	imported, invalid, err := h.Repo.ImportCrossReferences(refs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить кроссы"})
+		return
+	}
+
+	if errs == nil {
+		errs = []repo.RowError{}
+	}
+	for i, e := range invalid {
+		errs = append(errs, repo.RowError{Row: rowNums[i], Error: e.Error()})
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported": imported,
+		"skipped":  int64(len(refs)-len(invalid)) - imported, // уже были в базе
+		"errors":   errs,
+	})
+}
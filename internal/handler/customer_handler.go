@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// сколько покупателей отдавать в подсказке поиска
+const customerSearchLimit = 50
+
+type CustomerHandler struct {
+	Repo *repo.CustomerRepository
+}
+
+func NewCustomerHandler(db *gorm.DB) *CustomerHandler {
+	return &CustomerHandler{
+		Repo: repo.NewCustomerRepository(db),
+	}
+}
+
+// SearchCustomers — ?q=имя или телефон&type=retail|wholesale
+func (h *CustomerHandler) SearchCustomers(c *gin.Context) {
+	customers, err := h.Repo.SearchCustomers(c.Query("q"), c.Query("type"), customerSearchLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось найти покупателей"})
+		return
+	}
+	c.JSON(http.StatusOK, customers)
+}
+
+func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
+	var customer model.Customer
+	if err := c.ShouldBindJSON(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	// кредитный лимит даёт только менеджер
+	if customer.CreditLimit != 0 && !canSeeWholesale(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Кредитный лимит может установить только менеджер"})
+		return
+	}
+	// оптового покупателя заводит только менеджер
+	if !canSeeWholesale(c) && (isNonRetail(customer.Type) || isNonRetail(customer.PriceTier)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Оптового покупателя может завести только менеджер"})
+		return
+	}
+
+	if err := h.Repo.CreateCustomer(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, customer)
+}
+
+func isNonRetail(v string) bool {
+	return v != "" && v != model.CustomerRetail
+}
+
+func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var customer model.Customer
+	if err := c.ShouldBindJSON(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdateCustomer(id, &customer)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// GetCustomer — карточка покупателя с итогами покупок
+func (h *CustomerHandler) GetCustomer(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	customer, err := h.Repo.GetCustomer(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	stats, err := h.Repo.CustomerStats(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось посчитать покупки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"customer": customer,
+		"stats":    stats,
+	})
+}
+
+// GetCustomerHistory — чеки покупателя постранично: ?page=&pageSize=.
+// На первой странице также старые продажи без чека (legacySales).
+func (h *CustomerHandler) GetCustomerHistory(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	if _, err := h.Repo.GetCustomer(id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	page, pageSize := parsePage(c)
+	orders, total, err := h.Repo.CustomerOrders(id, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить историю покупок"})
+		return
+	}
+	for i := range orders {
+		hideSalesWholesale(c, orders[i].Lines)
+	}
+
+	legacy := []model.Sale{}
+	if page == 1 {
+		if legacy, err = h.Repo.CustomerLegacySales(id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить историю покупок"})
+			return
+		}
+		hideSalesWholesale(c, legacy)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"orders":      orders,
+		"legacySales": legacy,
+		"total":       total,
+		"page":        page,
+		"pageSize":    pageSize,
+	})
+}
+
+// GetCustomerBalance — долг покупателя, лимит и неоплаченные чеки
+func (h *CustomerHandler) GetCustomerBalance(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.Repo.CustomerBalance(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+func (h *CustomerHandler) GetPayments(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	payments, err := h.Repo.GetPayments(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить оплаты"})
+		return
+	}
+	c.JSON(http.StatusOK, payments)
+}
+
+// RecordPayment — покупатель гасит долг: {"amount": 5000, "orderId": 12, "note": ""}
+func (h *CustomerHandler) RecordPayment(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req repo.PaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	req.UserID = middleware.CurrentUserID(c)
+
+	payment, err := h.Repo.RecordPayment(id, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+// DebtAging — долги покупателей по срокам: 0–30, 31–60, 61–90 и больше 90 дней
+func (h *CustomerHandler) DebtAging(c *gin.Context) {
+	rows, err := h.Repo.AgingReport(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось построить отчёт по долгам"})
+		return
+	}
+
+	var total repo.AgingRow
+	for _, row := range rows {
+		total.Days0To30 += row.Days0To30
+		total.Days31To60 += row.Days31To60
+		total.Days61To90 += row.Days61To90
+		total.Over90 += row.Over90
+		total.Total += row.Total
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"customers": rows,
+		"totals":    total,
+	})
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"warehouse-backend/internal/repo"
+
+	"gorm.io/gorm"
+)
+
+// errorStatus подбирает HTTP-статус для ошибки из репозитория:
+// нехватка товара или превышен кредитный лимит — 409, нет прав — 403, не найдено — 404, остальное — ошибка в запросе
+func errorStatus(err error) int {
+	var stockErr *repo.InsufficientStockError
+	var creditErr *repo.CreditLimitError
+	switch {
+	case errors.As(err, &stockErr), errors.As(err, &creditErr):
+		return http.StatusConflict
+	case errors.Is(err, repo.ErrPriceOverrideForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
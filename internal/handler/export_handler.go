@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/service"
+	"warehouse-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startExport выставляет заголовки ответа и открывает выгрузку в формате ?format=csv|xlsx|pdf
+func startExport(c *gin.Context, name, title string, header []string) (service.ExportWriter, bool) {
+	format := c.DefaultQuery("format", service.ExportCSV)
+	switch format {
+	case service.ExportCSV, service.ExportXLSX, service.ExportPDF:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Формат должен быть csv, xlsx или pdf"})
+		return nil, false
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().In(utils.BusinessLocation()).Format("20060102"), format)
+	c.Header("Content-Type", service.ExportContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	w, err := service.NewExportWriter(format, c.Writer, title, header)
+	if err != nil {
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return w, true
+}
+
+// finishExport закрывает выгрузку. Ответ уже начат, так что ошибку можно только залогировать.
+func finishExport(w service.ExportWriter, err error) {
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		log.Println("Export error:", err)
+	}
+}
+
+// ExportItems — выгрузка товаров с теми же фильтрами, что и GET /api/items
+func (h *ItemHandler) ExportItems(c *gin.Context) {
+	filter, err := itemFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	withWholesale := canSeeWholesale(c)
+	header := []string{"ID", "Номер", "Наименование", "Бренд", "Модель", "Остаток", "Цена"}
+	if withWholesale {
+		header = append(header, "Оптовая цена", "Себестоимость")
+	}
+
+	w, ok := startExport(c, "items", "Товары", header)
+	if !ok {
+		return
+	}
+
+	err = h.Repo.EachItem(filter, w.RowLimit(), func(item *model.Item) error {
+		row := []interface{}{item.ID, item.PartNumber, item.Name, item.Brand, item.Model, item.Stock, item.Price}
+		if withWholesale {
+			row = append(row, item.WholesalePrice, item.LastCost)
+		}
+		return w.WriteRow(row)
+	})
+	finishExport(w, err)
+}
+
+// ExportSales — выгрузка продаж с фильтрами GET /api/sales: ?brand=&from=&to=
+func (h *ItemHandler) ExportSales(c *gin.Context) {
+	filter, err := salesFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	header := []string{"ID", "Чек", "Дата", "Номер", "Наименование", "Бренд", "Кол-во", "Цена", "Себестоимость", "Скидка", "Сумма", "Возвращено", "Сумма возврата", "Покупатель"}
+	w, ok := startExport(c, "sales", "Продажи", header)
+	if !ok {
+		return
+	}
+
+	err = h.Repo.EachSale(filter, w.RowLimit(), func(sale *model.Sale) error {
+		orderID := ""
+		if sale.OrderID != nil {
+			orderID = fmt.Sprint(*sale.OrderID)
+		}
+		return w.WriteRow([]interface{}{
+			sale.ID,
+			orderID,
+			sale.SoldAt.In(utils.BusinessLocation()).Format("2006-01-02 15:04"),
+			sale.Item.PartNumber,
+			sale.Item.Name,
+			sale.Item.Brand,
+			sale.Quantity,
+			sale.UnitPrice,
+			sale.UnitCost,
+			sale.DiscountAmount,
+			sale.TotalPrice,
+			sale.ReturnedQuantity,
+			sale.RefundedAmount,
+			sale.Customer,
+		})
+	})
+	finishExport(w, err)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportItems загружает прайс из CSV или XLSX (поле формы file).
+// dryRun=true — только показать, что будет создано и обновлено.
+// mapping — JSON {"Колонка в файле": "partNumber", ...}, если названия нестандартные.
+func (h *ItemHandler) ImportItems(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл не передан"})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.PostForm("dryRun"))
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат mapping"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось открыть файл"})
+		return
+	}
+	defer file.Close()
+
+	sheet, readErrs, err := service.ReadSheet(fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, parseErrs, err := service.ParseItemSheet(sheet, mapping)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.Repo.ImportItems(rows, dryRun, middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось загрузить товары"})
+		return
+	}
+	report.Errors = append(append(readErrs, parseErrs...), report.Errors...)
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+
+	c.JSON(http.StatusOK, report)
+}
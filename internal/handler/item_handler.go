@@ -12,8 +12,10 @@ import (
 	"strings"
 	"time"
 
+	"warehouse-backend/internal/middleware"
 	"warehouse-backend/internal/model"
 	"warehouse-backend/internal/repo"
+	"warehouse-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -43,6 +45,40 @@ func NewItemHandler(db *gorm.DB) *ItemHandler {
 		Repo: repo.NewItemRepository(db),
 	}
 }
+
+// canSeeWholesale — оптовую цену и себестоимость видят только администратор и менеджер
+func canSeeWholesale(c *gin.Context) bool {
+	role := middleware.CurrentRole(c)
+	return role == model.RoleAdmin || role == model.RoleManager
+}
+
+// canOverridePrice — ставить цену вручную могут только администратор и менеджер
+func canOverridePrice(c *gin.Context) bool {
+	return canSeeWholesale(c)
+}
+
+// hideWholesale убирает оптовую цену из ответа для остальных ролей
+func hideWholesale(c *gin.Context, items []model.Item) {
+	if canSeeWholesale(c) {
+		return
+	}
+	for i := range items {
+		items[i].WholesalePrice = 0
+		items[i].LastCost = 0
+	}
+}
+
+func hideSalesWholesale(c *gin.Context, sales []model.Sale) {
+	if canSeeWholesale(c) {
+		return
+	}
+	for i := range sales {
+		sales[i].Item.WholesalePrice = 0
+		sales[i].Item.LastCost = 0
+		sales[i].UnitCost = 0
+	}
+}
+
 func validateImage(fileHeader *multipart.FileHeader) error {
 	if fileHeader.Size > maxImageSize {
 		return fmt.Errorf("file too large (max 30MB)")
@@ -94,6 +130,9 @@ func (h *ItemHandler) AddItem(c *gin.Context) {
 	stock, _ := strconv.Atoi(c.PostForm("stock"))
 	price, _ := strconv.Atoi(c.PostForm("price"))
 	wholesalePrice, _ := strconv.Atoi(c.PostForm("wholesalePrice"))
+	lastCost, _ := strconv.Atoi(c.PostForm("lastCost"))
+	minStock, _ := strconv.Atoi(c.PostForm("minStock"))
+	reorderQty, _ := strconv.Atoi(c.PostForm("reorderQty"))
 
 	item := model.Item{
 		Name:           name,
@@ -103,6 +142,9 @@ func (h *ItemHandler) AddItem(c *gin.Context) {
 		Stock:          stock,
 		Price:          price,
 		WholesalePrice: wholesalePrice,
+		LastCost:       lastCost,
+		MinStock:       minStock,
+		ReorderQty:     reorderQty,
 	}
 
 	form, err := c.MultipartForm()
@@ -135,7 +177,7 @@ func (h *ItemHandler) AddItem(c *gin.Context) {
 		}
 	}
 
-	if err := h.Repo.AddItem(&item); err != nil {
+	if err := h.Repo.AddItem(&item, middleware.CurrentUserID(c)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not add item"})
 		return
 	}
@@ -154,33 +196,117 @@ func deleteLocalImage(path string) {
 	}
 }
 
-func (h *ItemHandler) GetItems(c *gin.Context) {
-	brand := c.Query("brand")
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
 
-	var (
-		items []model.Item
-		err   error
-	)
+// parsePage читает page и pageSize из запроса
+func parsePage(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.Query("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
 
-	if brand != "" {
-		items, err = h.Repo.GetItemsByBrand(brand)
-	} else {
-		items, err = h.Repo.GetAllItems()
+// pathID читает :id из пути; при неверном ID сразу отвечает 400
+func pathID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// optionalInt читает необязательный числовой параметр запроса
+func optionalInt(c *gin.Context, key string) (*int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("неверное значение %s", key)
+	}
+	return &v, nil
+}
+
+// itemFilterFromQuery читает фильтры и сортировку списка товаров:
+// ?brand=&model=&minPrice=&maxPrice=&inStock=true|false&sort=name|price|stock&order=asc|desc
+func itemFilterFromQuery(c *gin.Context) (repo.ItemFilter, error) {
+	filter := repo.ItemFilter{
+		Brand: c.Query("brand"),
+		Model: c.Query("model"),
+		Sort:  c.Query("sort"),
+		Desc:  c.Query("order") == "desc",
+	}
+
+	var err error
+	if filter.MinPrice, err = optionalInt(c, "minPrice"); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = optionalInt(c, "maxPrice"); err != nil {
+		return filter, err
+	}
+	if raw := c.Query("inStock"); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("неверное значение inStock")
+		}
+		filter.InStock = &inStock
+	}
+	return filter, nil
+}
+
+// GetItems — список товаров с фильтрами и сортировкой.
+// С &page= или &pageSize= — одна страница с общим числом, без них — весь список массивом, как раньше.
+func (h *ItemHandler) GetItems(c *gin.Context) {
+	filter, err := itemFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, hasPage := c.GetQuery("page")
+	_, hasPageSize := c.GetQuery("pageSize")
+	paged := hasPage || hasPageSize
+	if paged {
+		filter.Page, filter.PageSize = parsePage(c)
 	}
 
+	items, total, err := h.Repo.ListItems(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить список товаров"})
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	hideWholesale(c, items)
+	if !paged {
+		c.JSON(http.StatusOK, items)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":    items,
+		"total":    total,
+		"page":     filter.Page,
+		"pageSize": filter.PageSize,
+	})
 }
 
 func (h *ItemHandler) MakeSale(c *gin.Context) {
 	var req struct {
-		ItemID   uint   `json:"itemId"`
-		Quantity int    `json:"quantity"`
-		Customer string `json:"customer"`
+		repo.SaleLine
+		CustomerID *uint  `json:"customerId"`
+		Customer   string `json:"customer"`
+		PaidAmount *int   `json:"paidAmount"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -188,13 +314,22 @@ func (h *ItemHandler) MakeSale(c *gin.Context) {
 		return
 	}
 
-	sale, err := h.Repo.MakeSale(req.ItemID, req.Quantity, req.Customer)
+	sale, err := h.Repo.MakeSale(repo.OrderRequest{
+		CustomerID:         req.CustomerID,
+		Customer:           req.Customer,
+		PaidAmount:         req.PaidAmount,
+		Lines:              []repo.SaleLine{req.SaleLine},
+		UserID:             middleware.CurrentUserID(c),
+		AllowPriceOverride: canOverridePrice(c),
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, sale)
+	lines := []model.Sale{*sale}
+	hideSalesWholesale(c, lines)
+	c.JSON(http.StatusOK, lines[0])
 }
 
 func (h *ItemHandler) GetTodaySales(c *gin.Context) {
@@ -203,6 +338,7 @@ func (h *ItemHandler) GetTodaySales(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить продажи за сегодня"})
 		return
 	}
+	hideSalesWholesale(c, sales)
 	c.JSON(http.StatusOK, sales)
 }
 
@@ -215,25 +351,42 @@ func (h *ItemHandler) GetTop5BestSellers(c *gin.Context) {
 	c.JSON(http.StatusOK, top)
 }
 
-func (h *ItemHandler) GetSales(c *gin.Context) {
-	brand := c.Query("brand")
-
-	var (
-		sales []model.Sale
-		err   error
-	)
+// salesFilterFromQuery читает ?brand=&from=YYYY-MM-DD&to=YYYY-MM-DD (to включительно,
+// даты по времени магазина)
+func salesFilterFromQuery(c *gin.Context) (repo.SalesFilter, error) {
+	filter := repo.SalesFilter{Brand: c.Query("brand")}
+	if raw := c.Query("from"); raw != "" {
+		from, err := utils.ParseBusinessDate(raw)
+		if err != nil {
+			return filter, fmt.Errorf("неверная дата from")
+		}
+		filter.From = &from
+	}
+	if raw := c.Query("to"); raw != "" {
+		to, err := utils.ParseBusinessDate(raw)
+		if err != nil {
+			return filter, fmt.Errorf("неверная дата to")
+		}
+		to = utils.NextDay(to)
+		filter.To = &to
+	}
+	return filter, nil
+}
 
-	if brand != "" {
-		sales, err = h.Repo.GetSalesByBrand(brand)
-	} else {
-		sales, err = h.Repo.GetAllSales() // или можешь сделать GetAllSales()
+func (h *ItemHandler) GetSales(c *gin.Context) {
+	filter, err := salesFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
+	sales, err := h.Repo.ListSales(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить продажи"})
 		return
 	}
 
+	hideSalesWholesale(c, sales)
 	c.JSON(http.StatusOK, sales)
 }
 
@@ -264,6 +417,16 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 		if stock, err := strconv.Atoi(stockStr); err == nil {
 			updates["stock"] = stock
 		}
+		// остаток задаётся по месту хранения; без locationId — основное место
+		if locStr := c.PostForm("locationId"); locStr != "" {
+			locationID, err := strconv.ParseUint(locStr, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный locationId"})
+				return
+			}
+			loc := uint(locationID)
+			updates["stock_location_id"] = &loc
+		}
 	}
 	if priceStr := c.PostForm("price"); priceStr != "" {
 		if price, err := strconv.Atoi(priceStr); err == nil {
@@ -275,6 +438,21 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 			updates["wholesale_price"] = wholesale
 		}
 	}
+	if costStr := c.PostForm("lastCost"); costStr != "" {
+		if lastCost, err := strconv.Atoi(costStr); err == nil {
+			updates["last_cost"] = lastCost
+		}
+	}
+	if minStr := c.PostForm("minStock"); minStr != "" {
+		if minStock, err := strconv.Atoi(minStr); err == nil && minStock >= 0 {
+			updates["min_stock"] = minStock
+		}
+	}
+	if reorderStr := c.PostForm("reorderQty"); reorderStr != "" {
+		if reorderQty, err := strconv.Atoi(reorderStr); err == nil && reorderQty >= 0 {
+			updates["reorder_qty"] = reorderQty
+		}
+	}
 
 	// Обработка изображений
 	form, _ := c.MultipartForm()
@@ -300,11 +478,39 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 	}
 
 	// Обновление в репозитории
-	updatedItem, err := h.Repo.UpdateItem(uint(id), updates)
+	updatedItem, err := h.Repo.UpdateItem(uint(id), updates, middleware.CurrentUserID(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, updatedItem)
 }
+
+const defaultSearchLimit = 20
+
+// SearchItems — поиск по названию, номеру детали, бренду и модели: ?q=&limit=
+func (h *ItemHandler) SearchItems(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Пустой поисковый запрос"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	items, err := h.Repo.SearchItems(q, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить поиск"})
+		return
+	}
+
+	hideWholesale(c, items)
+	c.JSON(http.StatusOK, items)
+}
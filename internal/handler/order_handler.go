@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *ItemHandler) CreateOrder(c *gin.Context) {
+	var req repo.OrderRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	req.UserID = middleware.CurrentUserID(c)
+	req.AllowPriceOverride = canOverridePrice(c)
+
+	order, err := h.Repo.MakeOrder(req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	hideSalesWholesale(c, order.Lines)
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *ItemHandler) GetOrders(c *gin.Context) {
+	orders, err := h.Repo.GetOrders()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить чеки"})
+		return
+	}
+	for i := range orders {
+		hideSalesWholesale(c, orders[i].Lines)
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *ItemHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	order, err := h.Repo.GetOrder(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Чек не найден"})
+		return
+	}
+	hideSalesWholesale(c, order.Lines)
+	c.JSON(http.StatusOK, order)
+}
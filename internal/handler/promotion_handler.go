@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PromotionHandler struct {
+	Repo *repo.PromotionRepository
+}
+
+func NewPromotionHandler(db *gorm.DB) *PromotionHandler {
+	return &PromotionHandler{
+		Repo: repo.NewPromotionRepository(db),
+	}
+}
+
+// ListPromotions — ?active=true только действующие сейчас
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	promotions, err := h.Repo.ListPromotions(c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить список акций"})
+		return
+	}
+	c.JSON(http.StatusOK, promotions)
+}
+
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var promotion model.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.Repo.CreatePromotion(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	var promotion model.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdatePromotion(uint(id), &promotion)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
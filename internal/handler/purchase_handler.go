@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PurchaseHandler struct {
+	Repo *repo.PurchaseRepository
+}
+
+func NewPurchaseHandler(db *gorm.DB) *PurchaseHandler {
+	return &PurchaseHandler{
+		Repo: repo.NewPurchaseRepository(db),
+	}
+}
+
+func (h *PurchaseHandler) ListSuppliers(c *gin.Context) {
+	suppliers, err := h.Repo.ListSuppliers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить список поставщиков"})
+		return
+	}
+	c.JSON(http.StatusOK, suppliers)
+}
+
+func (h *PurchaseHandler) CreateSupplier(c *gin.Context) {
+	var supplier model.Supplier
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.Repo.CreateSupplier(&supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, supplier)
+}
+
+func (h *PurchaseHandler) UpdateSupplier(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var supplier model.Supplier
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdateSupplier(id, &supplier)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// ListPurchaseOrders — ?status=&supplierId=
+func (h *PurchaseHandler) ListPurchaseOrders(c *gin.Context) {
+	filter := repo.PurchaseOrderFilter{Status: c.Query("status")}
+	if raw := c.Query("supplierId"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный supplierId"})
+			return
+		}
+		filter.SupplierID = uint(id)
+	}
+
+	orders, err := h.Repo.ListPurchaseOrders(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить заказы поставщикам"})
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *PurchaseHandler) GetPurchaseOrder(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	po, err := h.Repo.GetPurchaseOrder(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+func (h *PurchaseHandler) CreatePurchaseOrder(c *gin.Context) {
+	var po model.PurchaseOrder
+	if err := c.ShouldBindJSON(&po); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	po.UserID = middleware.CurrentUserID(c)
+
+	if err := h.Repo.CreatePurchaseOrder(&po); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+func (h *PurchaseHandler) UpdatePurchaseOrder(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var po model.PurchaseOrder
+	if err := c.ShouldBindJSON(&po); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdatePurchaseOrder(id, &po)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *PurchaseHandler) SendPurchaseOrder(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	po, err := h.Repo.SendPurchaseOrder(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+func (h *PurchaseHandler) CancelPurchaseOrder(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	po, err := h.Repo.CancelPurchaseOrder(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+// ReceivePurchaseOrder — приёмка товара: {"lines": [{"lineId": 1, "quantity": 5, "unitCost": 1200}]},
+// пустое тело — принять всё, что ещё не пришло
+func (h *PurchaseHandler) ReceivePurchaseOrder(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req repo.ReceiptRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+			return
+		}
+	}
+	req.UserID = middleware.CurrentUserID(c)
+
+	po, err := h.Repo.ReceivePurchaseOrder(id, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLowStock — товары ниже точки заказа
+func (h *ItemHandler) GetLowStock(c *gin.Context) {
+	items, err := h.Repo.LowStockItems()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить товары с низким остатком"})
+		return
+	}
+	if !canSeeWholesale(c) {
+		for i := range items {
+			items[i].UnitCost = 0
+		}
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetSuggestedPurchases — список на закупку: ?groupBy=supplier|brand
+func (h *ItemHandler) GetSuggestedPurchases(c *gin.Context) {
+	groups, err := h.Repo.SuggestedPurchases(c.DefaultQuery("groupBy", repo.ReorderBySupplier))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetStockAlerts — уведомления о низком остатке: ?all=true вместе с просмотренными
+func (h *ItemHandler) GetStockAlerts(c *gin.Context) {
+	alerts, err := h.Repo.ListStockAlerts(c.Query("all") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить уведомления"})
+		return
+	}
+	if !canSeeWholesale(c) {
+		for i := range alerts {
+			if alerts[i].Item != nil {
+				alerts[i].Item.WholesalePrice = 0
+				alerts[i].Item.LastCost = 0
+			}
+		}
+	}
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (h *ItemHandler) AcknowledgeStockAlert(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	alert, err := h.Repo.AcknowledgeStockAlert(id, middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, alert)
+}
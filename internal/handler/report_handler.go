@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"warehouse-backend/internal/repo"
+	"warehouse-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReportDays = 30
+	defaultTopDays    = 7
+	defaultTopLimit   = 5
+	maxTopLimit       = 100
+)
+
+type ReportHandler struct {
+	Repo     *repo.ReportRepository
+	ItemRepo *repo.ItemRepository
+}
+
+func NewReportHandler(db *gorm.DB) *ReportHandler {
+	return &ReportHandler{
+		Repo:     repo.NewReportRepository(db),
+		ItemRepo: repo.NewItemRepository(db),
+	}
+}
+
+// reportPeriod берёт from/to из запроса, по умолчанию — последние days дней
+// включая сегодня, целыми днями по времени магазина
+func reportPeriod(c *gin.Context, days int) (repo.SalesFilter, time.Time, time.Time, error) {
+	filter, err := salesFilterFromQuery(c)
+	if err != nil {
+		return filter, time.Time{}, time.Time{}, err
+	}
+
+	to := utils.NextDay(time.Now())
+	if filter.To != nil {
+		to = *filter.To
+	}
+	from := utils.StartOfDay(to.AddDate(0, 0, -days))
+	if filter.From != nil {
+		from = *filter.From
+	}
+	return filter, from, to, nil
+}
+
+// parseLimit читает ?limit= в пределах [1, maxTopLimit]
+func parseLimit(c *gin.Context, def int) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		return def
+	}
+	if limit > maxTopLimit {
+		return maxTopLimit
+	}
+	return limit
+}
+
+// SalesReport — отчёт по продажам:
+// ?from=&to=&groupBy=day|week|month|brand|item|customer|cashier&brand=&sort=revenue|quantity|margin&limit=
+func (h *ReportHandler) SalesReport(c *gin.Context) {
+	filter, from, to, err := reportPeriod(c, defaultReportDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := repo.SalesReportQuery{
+		From:    from,
+		To:      to,
+		GroupBy: c.DefaultQuery("groupBy", repo.GroupByDay),
+		Brand:   filter.Brand,
+		Sort:    c.Query("sort"),
+	}
+	if !repo.ValidGroupBy(query.GroupBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная группировка"})
+		return
+	}
+	if c.Query("limit") != "" {
+		query.Limit = parseLimit(c, defaultTopLimit)
+	}
+
+	rows, err := h.Repo.SalesReport(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось построить отчёт"})
+		return
+	}
+	totals, err := h.Repo.SalesTotals(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось построить отчёт"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from,
+		"to":      to,
+		"groupBy": query.GroupBy,
+		"rows":    rows,
+		"totals":  totals,
+	})
+}
+
+// TopBestSellers — топ-N товаров по количеству: ?limit=&from=&to= (по умолчанию топ-5 за 7 дней)
+func (h *ReportHandler) TopBestSellers(c *gin.Context) {
+	_, from, to, err := reportPeriod(c, defaultTopDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	top, err := h.ItemRepo.GetTopBestSellers(parseLimit(c, defaultTopLimit), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить топ продаж"})
+		return
+	}
+	c.JSON(http.StatusOK, top)
+}
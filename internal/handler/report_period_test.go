@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"warehouse-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func utcTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// Окна отчётов считаются целыми днями по времени магазина, в том числе через перевод часов
+func TestReportPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		query    string
+		days     int
+		wantFrom string
+		wantTo   string
+	}{
+		{
+			name:     "Алматы: to включительно",
+			timezone: "Asia/Almaty",
+			query:    "from=2025-01-01&to=2025-01-31",
+			wantFrom: "2024-12-31T19:00:00Z",
+			wantTo:   "2025-01-31T19:00:00Z",
+		},
+		{
+			name:     "Алматы: по умолчанию последние 7 дней до to",
+			timezone: "Asia/Almaty",
+			query:    "to=2025-01-16",
+			days:     7,
+			wantFrom: "2025-01-09T19:00:00Z",
+			wantTo:   "2025-01-16T19:00:00Z",
+		},
+		{
+			name:     "Нью-Йорк: неделя с переходом на летнее время",
+			timezone: "America/New_York",
+			query:    "to=2024-03-12",
+			days:     7,
+			wantFrom: "2024-03-06T05:00:00Z",
+			wantTo:   "2024-03-13T04:00:00Z",
+		},
+		{
+			name:     "Берлин: один день возврата на зимнее время",
+			timezone: "Europe/Berlin",
+			query:    "from=2024-10-27&to=2024-10-27",
+			wantFrom: "2024-10-26T22:00:00Z",
+			wantTo:   "2024-10-27T23:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { _ = utils.LoadBusinessLocation() })
+			t.Setenv("BUSINESS_TIMEZONE", tt.timezone)
+			if err := utils.LoadBusinessLocation(); err != nil {
+				t.Fatal(err)
+			}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/reports/sales?"+tt.query, nil)
+
+			_, from, to, err := reportPeriod(c, tt.days)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := utcTime(t, tt.wantFrom); !from.Equal(want) {
+				t.Errorf("from = %v, want %v", from.UTC(), want)
+			}
+			if want := utcTime(t, tt.wantTo); !to.Equal(want) {
+				t.Errorf("to = %v, want %v", to.UTC(), want)
+			}
+		})
+	}
+}
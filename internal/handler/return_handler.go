@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *ItemHandler) ReturnSale(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	var req repo.ReturnRequest
+	// пустое тело — полный возврат позиции
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+			return
+		}
+	}
+	req.UserID = middleware.CurrentUserID(c)
+
+	ret, err := h.Repo.ReturnSale(uint(id), req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ret)
+}
+
+func (h *ItemHandler) GetSaleReturns(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	returns, err := h.Repo.GetSaleReturns(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить возвраты"})
+		return
+	}
+	c.JSON(http.StatusOK, returns)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"warehouse-backend/internal/db"
+	"warehouse-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Тесты гоняют настоящие параллельные продажи, поэтому нужна живая база:
+// TEST_DATABASE_DSN=postgres://... go test ./internal/handler/
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN не задан — тест с базой пропущен")
+	}
+	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("подключение к базе: %v", err)
+	}
+	db.AutoMigrate(conn)
+	return conn
+}
+
+func testItem(t *testing.T, h *ItemHandler, stock int) *model.Item {
+	t.Helper()
+	item := &model.Item{
+		Name:       "Тестовый товар",
+		PartNumber: fmt.Sprintf("TEST-%d", time.Now().UnixNano()),
+		Price:      1000,
+		Stock:      stock,
+	}
+	if err := h.Repo.AddItem(item, nil); err != nil {
+		t.Fatalf("создание товара: %v", err)
+	}
+	return item
+}
+
+func testRouter(h *ItemHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/sale", h.MakeSale)
+	r.POST("/orders", h.CreateOrder)
+	return r
+}
+
+func postJSON(r *gin.Engine, path string, body any) int {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+// Остаток 5, двадцать одновременных продаж по одной штуке: продаться должно
+// ровно 5, остальные получают 409, а остаток, журнал и места хранения сходятся.
+func TestConcurrentSalesDoNotOversell(t *testing.T) {
+	conn := testDB(t)
+	h := NewItemHandler(conn)
+	r := testRouter(h)
+	item := testItem(t, h, 5)
+
+	const attempts = 20
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = postJSON(r, "/sale", gin.H{"itemId": item.ID, "quantity": 1})
+		}()
+	}
+	wg.Wait()
+
+	counts := map[int]int{}
+	for _, code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusOK] != 5 || counts[http.StatusConflict] != attempts-5 {
+		t.Fatalf("ожидали 5×200 и %d×409, получили %v", attempts-5, counts)
+	}
+
+	var stock, sold, ledger, byLocation int64
+	conn.Model(&model.Item{}).Where("id = ?", item.ID).Select("stock").Scan(&stock)
+	conn.Model(&model.Sale{}).Where("item_id = ?", item.ID).Select("COALESCE(SUM(quantity), 0)").Scan(&sold)
+	conn.Model(&model.StockMovement{}).Where("item_id = ?", item.ID).Select("COALESCE(SUM(quantity), 0)").Scan(&ledger)
+	conn.Model(&model.ItemStock{}).Where("item_id = ?", item.ID).Select("COALESCE(SUM(quantity), 0)").Scan(&byLocation)
+	if stock != 0 || sold != 5 || ledger != 0 || byLocation != 0 {
+		t.Fatalf("остаток %d, продано %d, по журналу %d, по местам %d", stock, sold, ledger, byLocation)
+	}
+}
+
+// Чеки с теми же товарами в обратном порядке не должны ждать друг друга по кругу
+func TestConcurrentOrdersOppositeLineOrder(t *testing.T) {
+	conn := testDB(t)
+	h := NewItemHandler(conn)
+	r := testRouter(h)
+	a := testItem(t, h, 100)
+	b := testItem(t, h, 100)
+
+	const attempts = 20
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := range attempts {
+		first, second := a.ID, b.ID
+		if i%2 == 1 {
+			first, second = second, first
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = postJSON(r, "/orders", gin.H{"lines": []gin.H{
+				{"itemId": first, "quantity": 1},
+				{"itemId": second, "quantity": 1},
+			}})
+		}()
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("чек %d: статус %d", i, code)
+		}
+	}
+	for _, item := range []*model.Item{a, b} {
+		var stock int64
+		conn.Model(&model.Item{}).Where("id = ?", item.ID).Select("stock").Scan(&stock)
+		if stock != 100-attempts {
+			t.Fatalf("товар %d: остаток %d, ожидали %d", item.ID, stock, 100-attempts)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *ItemHandler) GetStockMovements(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	movements, err := h.Repo.GetStockMovements(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить движение товара"})
+		return
+	}
+	c.JSON(http.StatusOK, movements)
+}
+
+// AddStockMovement — ручной приход, корректировка или списание
+func (h *ItemHandler) AddStockMovement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	var req struct {
+		Type       string `json:"type"`
+		Quantity   int    `json:"quantity"`
+		Reason     string `json:"reason"`
+		LocationID *uint  `json:"locationId"` // пусто — основное место
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	item, err := h.Repo.AddStockMovement(&model.StockMovement{
+		ItemID:     uint(id),
+		Type:       req.Type,
+		Quantity:   req.Quantity,
+		LocationID: req.LocationID,
+		UserID:     middleware.CurrentUserID(c),
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ItemHandler) ReconcileStock(c *gin.Context) {
+	mismatches, err := h.Repo.ReconcileStock()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сверить остатки"})
+		return
+	}
+	c.JSON(http.StatusOK, mismatches)
+}
+
+// GetItemStock — остатки товара по складам и местам хранения
+func (h *ItemHandler) GetItemStock(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	stocks, err := h.Repo.GetItemStock(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stocks)
+}
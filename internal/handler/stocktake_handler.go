@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hideStocktakeWholesale убирает оптовую цену товаров для остальных ролей
+func hideStocktakeWholesale(c *gin.Context, lines []model.StocktakeLine) {
+	if canSeeWholesale(c) {
+		return
+	}
+	for i := range lines {
+		if lines[i].Item != nil {
+			lines[i].Item.WholesalePrice = 0
+			lines[i].Item.LastCost = 0
+		}
+	}
+}
+
+// ListStocktakes — ?status=open|approved|cancelled
+func (h *ItemHandler) ListStocktakes(c *gin.Context) {
+	stocktakes, err := h.Repo.ListStocktakes(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить инвентаризации"})
+		return
+	}
+	c.JSON(http.StatusOK, stocktakes)
+}
+
+// CreateStocktake — {"locationId": 2, "brand": "Toyota", "notes": ""}; пустые поля — без ограничения
+func (h *ItemHandler) CreateStocktake(c *gin.Context) {
+	var stocktake model.Stocktake
+	if err := c.ShouldBindJSON(&stocktake); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	stocktake.UserID = middleware.CurrentUserID(c)
+
+	if err := h.Repo.CreateStocktake(&stocktake); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stocktake)
+}
+
+func (h *ItemHandler) GetStocktake(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	stocktake, err := h.Repo.GetStocktake(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	hideStocktakeWholesale(c, stocktake.Lines)
+	c.JSON(http.StatusOK, stocktake)
+}
+
+// RecordCounts — {"counts": [{"lineId": 1, "counted": 4}, {"itemId": 7, "locationId": 2, "counted": 1}]}
+func (h *ItemHandler) RecordCounts(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Counts []repo.StocktakeCount `json:"counts"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	userID := middleware.CurrentUserID(c)
+	for i := range req.Counts {
+		req.Counts[i].UserID = userID
+	}
+
+	lines, err := h.Repo.RecordCounts(id, req.Counts)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lines)
+}
+
+// GetStocktakeVariance — расхождения и их стоимость
+func (h *ItemHandler) GetStocktakeVariance(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.Repo.StocktakeVariance(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ApproveStocktake — {"reason": "плановая инвентаризация"}
+func (h *ItemHandler) ApproveStocktake(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	report, err := h.Repo.ApproveStocktake(id, req.Reason, middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ItemHandler) CancelStocktake(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Repo.CancelStocktake(id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": model.StocktakeCancelled})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+
+	"warehouse-backend/internal/middleware"
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hideTransferWholesale убирает оптовую цену товаров в позициях для остальных ролей
+func hideTransferWholesale(c *gin.Context, t *model.Transfer) {
+	if canSeeWholesale(c) {
+		return
+	}
+	for i := range t.Lines {
+		if t.Lines[i].Item != nil {
+			t.Lines[i].Item.WholesalePrice = 0
+			t.Lines[i].Item.LastCost = 0
+		}
+	}
+}
+
+// ListTransfers — ?status=draft|shipped|received|cancelled
+func (h *ItemHandler) ListTransfers(c *gin.Context) {
+	transfers, err := h.Repo.ListTransfers(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить перемещения"})
+		return
+	}
+	for i := range transfers {
+		hideTransferWholesale(c, &transfers[i])
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *ItemHandler) GetTransfer(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.Repo.GetTransfer(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	hideTransferWholesale(c, transfer)
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *ItemHandler) CreateTransfer(c *gin.Context) {
+	var transfer model.Transfer
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+	transfer.UserID = middleware.CurrentUserID(c)
+
+	if err := h.Repo.CreateTransfer(&transfer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *ItemHandler) UpdateTransfer(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var transfer model.Transfer
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdateTransfer(id, &transfer)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// ShipTransfer — отгрузка: товар уходит с места отправки
+func (h *ItemHandler) ShipTransfer(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.Repo.ShipTransfer(id, middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+// ReceiveTransfer — приёмка: {"lines": [{"lineId": 1, "quantity": 3, "note": "одна разбита"}]},
+// пустое тело — всё пришло полностью
+func (h *ItemHandler) ReceiveTransfer(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req repo.TransferReceipt
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+			return
+		}
+	}
+	req.UserID = middleware.CurrentUserID(c)
+
+	transfer, err := h.Repo.ReceiveTransfer(id, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *ItemHandler) CancelTransfer(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.Repo.CancelTransfer(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
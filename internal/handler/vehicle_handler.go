@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type VehicleHandler struct {
+	Repo *repo.VehicleRepository
+}
+
+func NewVehicleHandler(db *gorm.DB) *VehicleHandler {
+	return &VehicleHandler{
+		Repo: repo.NewVehicleRepository(db),
+	}
+}
+
+// vehicleFilter читает ?make=&model=&year=&engine=
+func vehicleFilter(c *gin.Context) (repo.VehicleFilter, error) {
+	filter := repo.VehicleFilter{
+		Make:   c.Query("make"),
+		Model:  c.Query("model"),
+		Engine: c.Query("engine"),
+	}
+	if raw := c.Query("year"); raw != "" {
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			return filter, errors.New("неверный год")
+		}
+		filter.Year = year
+	}
+	return filter, nil
+}
+
+func (h *VehicleHandler) ListVehicles(c *gin.Context) {
+	filter, err := vehicleFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vehicles, err := h.Repo.ListVehicles(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить список автомобилей"})
+		return
+	}
+	c.JSON(http.StatusOK, vehicles)
+}
+
+func (h *VehicleHandler) CreateVehicle(c *gin.Context) {
+	var vehicle model.Vehicle
+	if err := c.ShouldBindJSON(&vehicle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.Repo.CreateVehicle(&vehicle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, vehicle)
+}
+
+func (h *VehicleHandler) UpdateVehicle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	var vehicle model.Vehicle
+	if err := c.ShouldBindJSON(&vehicle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdateVehicle(uint(id), &vehicle)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// FindParts — запчасти для автомобиля: ?make=&model=&year=&engine=&inStock=true
+func (h *VehicleHandler) FindParts(c *gin.Context) {
+	filter, err := vehicleFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Make == "" && filter.Model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите марку или модель"})
+		return
+	}
+
+	items, err := h.Repo.FindItemsForVehicle(filter, c.Query("inStock") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось подобрать запчасти"})
+		return
+	}
+
+	hideWholesale(c, items)
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *VehicleHandler) GetItemVehicles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	vehicles, err := h.Repo.GetItemVehicles(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить применимость"})
+		return
+	}
+	c.JSON(http.StatusOK, vehicles)
+}
+
+func (h *VehicleHandler) AddItemVehicles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+
+	var req struct {
+		VehicleIDs []uint `json:"vehicleIds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.Repo.AddFitment(uint(id), req.VehicleIDs); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	vehicles, err := h.Repo.GetItemVehicles(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить применимость"})
+		return
+	}
+	c.JSON(http.StatusOK, vehicles)
+}
+
+func (h *VehicleHandler) RemoveItemVehicle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID"})
+		return
+	}
+	vehicleID, err := strconv.ParseUint(c.Param("vehicleId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID автомобиля"})
+		return
+	}
+
+	if err := h.Repo.RemoveFitment(uint(id), uint(vehicleID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+
+	"warehouse-backend/internal/model"
+	"warehouse-backend/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WarehouseHandler struct {
+	Repo *repo.WarehouseRepository
+}
+
+func NewWarehouseHandler(db *gorm.DB) *WarehouseHandler {
+	return &WarehouseHandler{
+		Repo: repo.NewWarehouseRepository(db),
+	}
+}
+
+func (h *WarehouseHandler) ListWarehouses(c *gin.Context) {
+	warehouses, err := h.Repo.ListWarehouses()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить список складов"})
+		return
+	}
+	c.JSON(http.StatusOK, warehouses)
+}
+
+func (h *WarehouseHandler) CreateWarehouse(c *gin.Context) {
+	var warehouse model.Warehouse
+	if err := c.ShouldBindJSON(&warehouse); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.Repo.CreateWarehouse(&warehouse); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, warehouse)
+}
+
+func (h *WarehouseHandler) UpdateWarehouse(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var warehouse model.Warehouse
+	if err := c.ShouldBindJSON(&warehouse); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdateWarehouse(id, &warehouse)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *WarehouseHandler) CreateLocation(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var location model.Location
+	if err := c.ShouldBindJSON(&location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.Repo.CreateLocation(id, &location); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, location)
+}
+
+func (h *WarehouseHandler) UpdateLocation(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var location model.Location
+	if err := c.ShouldBindJSON(&location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	updated, err := h.Repo.UpdateLocation(id, &location)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// GetLocationStock — что лежит в месте хранения
+func (h *WarehouseHandler) GetLocationStock(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	stocks, err := h.Repo.LocationStock(id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	for i := range stocks {
+		if stocks[i].Item != nil && !canSeeWholesale(c) {
+			stocks[i].Item.WholesalePrice = 0
+			stocks[i].Item.LastCost = 0
+		}
+	}
+	c.JSON(http.StatusOK, stocks)
+}
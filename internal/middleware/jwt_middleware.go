@@ -7,6 +7,13 @@ import (
 	"warehouse-backend/internal/service"
 )
 
+// Ключи контекста, которые заполняет AuthMiddleware
+const (
+	ContextUserID   = "userID"
+	ContextUsername = "username"
+	ContextRole     = "role"
+)
+
 func AuthMiddleware(tokenService *service.JWTService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -17,12 +24,36 @@ func AuthMiddleware(tokenService *service.JWTService) gin.HandlerFunc {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		_, err := tokenService.ValidateToken(tokenString)
+		claims, err := tokenService.ValidateToken(tokenString)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
 		}
 
+		// числа в MapClaims приходят как float64
+		if id, ok := claims["user_id"].(float64); ok {
+			c.Set(ContextUserID, uint(id))
+		}
+		if username, ok := claims["username"].(string); ok {
+			c.Set(ContextUsername, username)
+		}
+		if role, ok := claims["role"].(string); ok {
+			c.Set(ContextRole, role)
+		}
+
 		c.Next()
 	}
 }
+
+// CurrentUserID возвращает ID пользователя из токена или nil
+func CurrentUserID(c *gin.Context) *uint {
+	v, ok := c.Get(ContextUserID)
+	if !ok {
+		return nil
+	}
+	id, ok := v.(uint)
+	if !ok {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// CrossReference — связь двух взаимозаменяемых номеров (OEM ↔ аналог).
+// Связь двусторонняя: аналоги ищутся в обе стороны и по цепочке.
+type CrossReference struct {
+	ID                   uint      `gorm:"primaryKey" json:"id"`
+	Brand                string    `gorm:"uniqueIndex:idx_cross_reference_pair" json:"brand"`
+	PartNumber           string    `json:"partNumber"`
+	PartNumberNorm       string    `gorm:"index;uniqueIndex:idx_cross_reference_pair" json:"-"`
+	AnalogBrand          string    `gorm:"uniqueIndex:idx_cross_reference_pair" json:"analogBrand"`
+	AnalogPartNumber     string    `json:"analogPartNumber"`
+	AnalogPartNumberNorm string    `gorm:"index;uniqueIndex:idx_cross_reference_pair" json:"-"`
+	Source               string    `json:"source"` // откуда связь: каталог, поставщик
+	CreatedAt            time.Time `json:"createdAt"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Типы покупателей
+const (
+	CustomerRetail    = "retail"
+	CustomerWholesale = "wholesale"
+)
+
+// Customer — покупатель из справочника. Продажи ссылаются на него по ID,
+// Sale.Customer остаётся как имя на момент продажи.
+type Customer struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	Name      string `json:"name"`
+	NameKey   string `gorm:"index" json:"-"` // имя для сравнения, см. CustomerNameKey
+	Phone     string `gorm:"index" json:"phone"`
+	Type      string `gorm:"default:retail" json:"type"`
+	PriceTier string `gorm:"default:retail" json:"priceTier"` // уровень цены по умолчанию
+	Notes     string `json:"notes"`
+
+	CreditLimit int       `json:"creditLimit"` // сколько можно быть должным; 0 — только за наличные
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// CustomerNameKey приводит имя к виду для сравнения: «Азамат  СТО» и «азамат сто» совпадают
+func CustomerNameKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// CustomerNameKeySQL — то же самое на стороне базы, для переноса старых продаж
+func CustomerNameKeySQL(column string) string {
+	return "regexp_replace(lower(trim(" + column + ")), '\\s+', ' ', 'g')"
+}
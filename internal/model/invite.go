@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Invite — одноразовое приглашение на регистрацию с заранее выбранной ролью.
+// Сам токен не хранится, только его SHA-256.
+type Invite struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TokenHash   string     `gorm:"uniqueIndex" json:"-"`
+	Role        string     `json:"role"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	UsedAt      *time.Time `json:"usedAt"`
+	UsedByID    *uint      `json:"usedById"`
+	CreatedByID *uint      `json:"createdById"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
@@ -6,11 +6,16 @@ type Item struct {
 	PartNumber     string      `json:"partNumber"`
 	Brand          string      `json:"brand"`
 	Model          string      `json:"model"`
-	Stock          int         `json:"stock"`
+	Stock          int         `json:"stock"`      // всего по всем местам хранения
+	MinStock       int         `json:"minStock"`   // точка заказа: меньше — пора заказывать, 0 — не следим
+	ReorderQty     int         `json:"reorderQty"` // сколько заказывать за раз
 	Price          int         `json:"price"`
-	WholesalePrice int         `gorm:"column:wholesale_price" json:"wholesalePrice"`
+	WholesalePrice int         `gorm:"column:wholesale_price" json:"wholesalePrice,omitempty"` // цена продажи оптовым покупателям, не себестоимость
+	LastCost       int         `json:"lastCost,omitempty"`                                     // цена последней закупки — себестоимость продаж
 	Images         []ItemImage `gorm:"foreignKey:ItemID" json:"images"`
 	Sales          []Sale      `gorm:"foreignKey:ItemID"`
+	Vehicles       []Vehicle   `gorm:"many2many:item_fitments;" json:"vehicles,omitempty"` // на какие автомобили подходит
+	Locations      []ItemStock `gorm:"foreignKey:ItemID" json:"locations,omitempty"`       // где лежит
 }
 
 var allowedFields = map[string]string{
@@ -21,4 +26,7 @@ var allowedFields = map[string]string{
 	"model":          "model",
 	"partNumber":     "part_number",
 	"wholesalePrice": "wholesale_price", // 👈 вот ключ
+	"lastCost":       "last_cost",
+	"minStock":       "min_stock",
+	"reorderQty":     "reorder_qty",
 }
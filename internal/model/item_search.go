@@ -0,0 +1,18 @@
+package model
+
+// Кириллические буквы, которые пишутся так же, как латинские. При поиске
+// они заменяются на латиницу, чтобы «С» и «C» в номере детали совпадали.
+const (
+	SearchCyrillicLookalikes = "авекмнорстух"
+	SearchLatinLookalikes    = "abekmhopctyx"
+)
+
+// SQL-выражения для поиска. Используются и в индексах (db.AutoMigrate), и в запросах —
+// текст должен совпадать дословно, иначе Postgres не возьмёт индекс.
+const (
+	// номер детали без регистра, дефисов, пробелов и с латиницей вместо похожей кириллицы
+	ItemPartNumberNormSQL = `regexp_replace(translate(lower(part_number), '` + SearchCyrillicLookalikes + `', '` + SearchLatinLookalikes + `'), '[^0-9a-zа-яё]', '', 'g')`
+
+	// название, бренд, модель и номер одной строкой для полнотекстового и нечёткого поиска
+	ItemSearchTextSQL = `translate(lower(coalesce(name, '') || ' ' || coalesce(brand, '') || ' ' || coalesce(model, '') || ' ' || coalesce(part_number, '')), '` + SearchCyrillicLookalikes + `', '` + SearchLatinLookalikes + `')`
+)
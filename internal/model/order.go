@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Статусы оплаты чека
+const (
+	PaymentPaid    = "paid"
+	PaymentUnpaid  = "unpaid"
+	PaymentPartial = "partial"
+)
+
+// Order — чек: одна покупка с несколькими позициями (model.Sale)
+type Order struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	SoldAt     time.Time `json:"soldAt"`                  // дата продажи
+	Customer   string    `json:"customer"`                // кому продано
+	CustomerID *uint     `gorm:"index" json:"customerId"` // покупатель из справочника
+	TotalPrice int       `json:"totalPrice"`              // сумма по всем позициям
+	UserID     *uint     `json:"userId"`                  // кассир
+	Lines      []Sale    `gorm:"foreignKey:OrderID" json:"lines"`
+
+	// Оплата: долг по чеку = TotalPrice - RefundedAmount - PaidAmount
+	PaidAmount     int    `json:"paidAmount"`                              // сколько заплачено
+	RefundedAmount int    `json:"refundedAmount"`                          // сумма возвратов по всем позициям
+	PaymentStatus  string `gorm:"index;default:paid" json:"paymentStatus"` // paid, unpaid или partial
+}
+
+// Due — сколько ещё должен покупатель по чеку
+func (o *Order) Due() int {
+	return o.TotalPrice - o.RefundedAmount - o.PaidAmount
+}
+
+// UpdatePaymentStatus пересчитывает статус оплаты по суммам
+func (o *Order) UpdatePaymentStatus() {
+	switch {
+	case o.Due() <= 0:
+		o.PaymentStatus = PaymentPaid
+	case o.PaidAmount == 0:
+		o.PaymentStatus = PaymentUnpaid
+	default:
+		o.PaymentStatus = PaymentPartial
+	}
+}
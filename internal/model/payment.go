@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Payment — оплата долга покупателем. Сумма распределяется по его
+// неоплаченным чекам, начиная со старых, или идёт в указанный чек.
+type Payment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CustomerID uint      `gorm:"index" json:"customerId"`
+	OrderID    *uint     `gorm:"index" json:"orderId"` // если платят за конкретный чек
+	Amount     int       `json:"amount"`
+	Note       string    `json:"note"`
+	UserID     *uint     `json:"userId"` // кто принял оплату
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Promotion — скидка в процентах на товар или на весь бренд на период.
+// Действует на розничную цену, если кассир не дал скидку больше.
+type Promotion struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `json:"name"`
+	ItemID    *uint      `gorm:"index" json:"itemId"` // на товар
+	Brand     string     `gorm:"index" json:"brand"`  // или на бренд, если ItemID пуст
+	Percent   int        `json:"percent"`
+	StartsAt  time.Time  `json:"startsAt"`
+	EndsAt    *time.Time `json:"endsAt"` // nil — бессрочно
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
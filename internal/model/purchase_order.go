@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Статусы заказа поставщику
+const (
+	PurchaseDraft             = "draft"              // черновик, можно править
+	PurchaseSent              = "sent"               // отправлен поставщику
+	PurchasePartiallyReceived = "partially_received" // пришла часть товара
+	PurchaseReceived          = "received"           // пришло всё
+	PurchaseCancelled         = "cancelled"          // отменён
+)
+
+// PurchaseOrder — заказ поставщику
+type PurchaseOrder struct {
+	ID         uint                `gorm:"primaryKey" json:"id"`
+	SupplierID uint                `gorm:"index" json:"supplierId"`
+	Supplier   Supplier            `gorm:"foreignKey:SupplierID" json:"supplier"`
+	Status     string              `gorm:"index;default:draft" json:"status"`
+	Notes      string              `json:"notes"`
+	UserID     *uint               `json:"userId"` // кто создал
+	CreatedAt  time.Time           `json:"createdAt"`
+	SentAt     *time.Time          `json:"sentAt"`
+	ReceivedAt *time.Time          `json:"receivedAt"` // когда пришёл последний товар
+	Lines      []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID" json:"lines"`
+}
+
+// PurchaseOrderLine — позиция заказа поставщику
+type PurchaseOrderLine struct {
+	ID               uint `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID  uint `gorm:"index" json:"purchaseOrderId"`
+	ItemID           uint `gorm:"index" json:"itemId"`
+	Item             Item `gorm:"foreignKey:ItemID" json:"item"`
+	Quantity         int  `json:"quantity"`         // заказано
+	UnitCost         int  `json:"unitCost"`         // цена закупки за штуку, после приёмки — фактическая
+	ReceivedQuantity int  `json:"receivedQuantity"` // сколько уже пришло
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// RefreshToken — серверная сессия пользователя. При каждом обновлении токен
+// заменяется новым, в базе хранится только хеш.
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"index" json:"userId"`
+	TokenHash    string     `gorm:"uniqueIndex" json:"-"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	RevokedAt    *time.Time `json:"revokedAt"`
+	ReplacedByID *uint      `json:"replacedById"` // токен, выданный взамен при ротации
+	CreatedAt    time.Time  `json:"createdAt"`
+}
@@ -2,12 +2,40 @@ package model
 
 import "time"
 
+// Ценовые уровни
+const (
+	PriceTierRetail    = "retail"
+	PriceTierWholesale = "wholesale"
+)
+
 type Sale struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
+	OrderID    *uint     `gorm:"index" json:"orderId"` // чек, к которому относится позиция
 	ItemID     uint      `json:"itemId"`
 	Item       Item      `gorm:"foreignKey:ItemID"`
-	SoldAt     time.Time `json:"soldAt"`     // дата продажи
-	Quantity   int       `json:"quantity"`   // количество
-	TotalPrice int       `json:"totalPrice"` // общая сумма
-	Customer   string    `json:"customer"`   // кому продано
+	SoldAt     time.Time `json:"soldAt"`                  // дата продажи
+	Quantity   int       `json:"quantity"`                // количество
+	TotalPrice int       `json:"totalPrice"`              // общая сумма
+	Customer   string    `json:"customer"`                // кому продано
+	CustomerID *uint     `gorm:"index" json:"customerId"` // покупатель из справочника
+	UserID     *uint     `json:"userId"`                  // кассир
+	LocationID *uint     `json:"locationId"`              // откуда продано
+
+	// Цена и себестоимость единицы на момент продажи — не меняются при правке карточки товара
+	UnitPrice int `json:"unitPrice"`
+	UnitCost  int `json:"unitCost,omitempty"`
+
+	// Как получилась цена: TotalPrice = UnitPrice * Quantity - DiscountAmount
+	PriceTier       string `json:"priceTier"`       // retail или wholesale
+	ListPrice       int    `json:"listPrice"`       // розничная цена из карточки
+	PriceOverridden bool   `json:"priceOverridden"` // цену задали вручную
+	DiscountAmount  int    `json:"discountAmount"`  // скидка на всю позицию
+	PromotionID     *uint  `json:"promotionId"`     // акция, если скидка по акции
+
+	ReturnedQuantity int `json:"returnedQuantity"` // сколько из Quantity вернули
+	RefundedAmount   int `json:"refundedAmount"`   // сколько из TotalPrice вернули деньгами
+
+	// За вычетом возвратов — не хранятся, считаются при выдаче списка продаж
+	NetQuantity int `gorm:"-" json:"netQuantity"`
+	NetTotal    int `gorm:"-" json:"netTotal"`
 }
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// SaleReturn — возврат товара по позиции чека
+type SaleReturn struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SaleID       uint      `gorm:"index" json:"saleId"`
+	ItemID       uint      `json:"itemId"`
+	Quantity     int       `json:"quantity"`     // сколько вернули
+	RefundAmount int       `json:"refundAmount"` // на сколько уменьшилась сумма продажи
+	DebtReduced  int       `json:"debtReduced"`  // из них списано с долга, а не выдано деньгами
+	Reason       string    `json:"reason"`
+	UserID       *uint     `json:"userId"`
+	ReturnedAt   time.Time `json:"returnedAt"`
+}
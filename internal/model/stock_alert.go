@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// StockAlert — уведомление: продажа опустила остаток товара ниже точки заказа
+type StockAlert struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	ItemID         uint       `gorm:"index" json:"itemId"`
+	Item           *Item      `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	OrderID        *uint      `json:"orderId"`  // чек, после которого сработало
+	Stock          int        `json:"stock"`    // остаток после продажи
+	MinStock       int        `json:"minStock"` // точка заказа на тот момент
+	CreatedAt      time.Time  `gorm:"index" json:"createdAt"`
+	Acknowledged   bool       `gorm:"index" json:"acknowledged"`
+	AcknowledgedBy *uint      `json:"acknowledgedBy"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt"`
+}
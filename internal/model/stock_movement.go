@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Типы движения товара
+const (
+	MovementReceipt    = "receipt"    // приход
+	MovementSale       = "sale"       // продажа
+	MovementReturn     = "return"     // возврат от покупателя
+	MovementAdjustment = "adjustment" // корректировка
+	MovementWriteOff   = "write_off"  // списание
+	MovementTransfer   = "transfer"   // перемещение
+)
+
+// StockMovement — запись журнала движения товара. Журнал только дополняется:
+// сумма Quantity по товару равна Item.Stock.
+type StockMovement struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ItemID          uint      `gorm:"index" json:"itemId"`
+	Type            string    `gorm:"index" json:"type"`
+	Quantity        int       `json:"quantity"`                // изменение остатка: + приход, - расход
+	LocationID      *uint     `gorm:"index" json:"locationId"` // место хранения
+	UserID          *uint     `json:"userId"`                  // кто провёл
+	Reason          string    `json:"reason"`
+	SaleID          *uint     `gorm:"index" json:"saleId"`                    // позиция чека для продаж и возвратов
+	PurchaseOrderID *uint     `gorm:"index" json:"purchaseOrderId,omitempty"` // заказ поставщику для прихода
+	TransferID      *uint     `gorm:"index" json:"transferId,omitempty"`      // перемещение между местами
+	StocktakeID     *uint     `gorm:"index" json:"stocktakeId,omitempty"`     // инвентаризация для корректировок
+	CreatedAt       time.Time `gorm:"index" json:"createdAt"`
+}
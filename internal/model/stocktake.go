@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Статусы инвентаризации
+const (
+	StocktakeOpen      = "open"      // идёт подсчёт
+	StocktakeApproved  = "approved"  // расхождения проведены корректировками
+	StocktakeCancelled = "cancelled" // отменена без изменений остатков
+)
+
+// Stocktake — инвентаризация: снимок ожидаемых остатков по месту хранения
+// и/или бренду, подсчитанные количества и расхождения
+type Stocktake struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	Status     string          `gorm:"index;default:open" json:"status"`
+	LocationID *uint           `json:"locationId"` // пусто — все места
+	Brand      string          `json:"brand"`      // пусто — все бренды
+	Notes      string          `json:"notes"`
+	Reason     string          `json:"reason"` // причина корректировок при утверждении
+	UserID     *uint           `json:"userId"` // кто начал
+	ApprovedBy *uint           `json:"approvedBy"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	ApprovedAt *time.Time      `json:"approvedAt"`
+	Lines      []StocktakeLine `gorm:"foreignKey:StocktakeID" json:"lines,omitempty"`
+}
+
+// StocktakeLine — товар в месте хранения: сколько ожидали и сколько насчитали.
+// Variance = Counted - Expected, пока не посчитано — 0.
+type StocktakeLine struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	StocktakeID uint       `gorm:"index" json:"stocktakeId"`
+	ItemID      uint       `gorm:"index" json:"itemId"`
+	Item        *Item      `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	LocationID  uint       `json:"locationId"`
+	Location    *Location  `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+	Expected    int        `json:"expected"`
+	Counted     *int       `json:"counted"` // пусто — ещё не посчитано
+	Variance    int        `json:"variance"`
+	CountedBy   *uint      `json:"countedBy"`
+	CountedAt   *time.Time `json:"countedAt"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Supplier — поставщик запчастей
+type Supplier struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
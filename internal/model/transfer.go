@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Статусы перемещения
+const (
+	TransferDraft     = "draft"     // черновик, можно править
+	TransferShipped   = "shipped"   // отгружено, товар в пути
+	TransferReceived  = "received"  // принято на месте назначения
+	TransferCancelled = "cancelled" // отменено до отгрузки
+)
+
+// Transfer — перемещение товара между местами хранения, в том числе между складами.
+// При отгрузке товар списывается с места-источника и до приёмки не числится нигде,
+// но в расчёте нехватки и закупки учитывается как товар в пути.
+type Transfer struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	FromLocationID uint           `gorm:"index" json:"fromLocationId"`
+	FromLocation   *Location      `gorm:"foreignKey:FromLocationID" json:"fromLocation,omitempty"`
+	ToLocationID   uint           `gorm:"index" json:"toLocationId"`
+	ToLocation     *Location      `gorm:"foreignKey:ToLocationID" json:"toLocation,omitempty"`
+	Status         string         `gorm:"index;default:draft" json:"status"`
+	Notes          string         `json:"notes"`
+	UserID         *uint          `json:"userId"` // кто создал
+	CreatedAt      time.Time      `json:"createdAt"`
+	ShippedAt      *time.Time     `json:"shippedAt"`
+	ReceivedAt     *time.Time     `json:"receivedAt"`
+	Lines          []TransferLine `gorm:"foreignKey:TransferID" json:"lines"`
+}
+
+// TransferLine — позиция перемещения. Расхождение — сколько отгрузили, но не приняли.
+type TransferLine struct {
+	ID               uint   `gorm:"primaryKey" json:"id"`
+	TransferID       uint   `gorm:"index" json:"transferId"`
+	ItemID           uint   `gorm:"index" json:"itemId"`
+	Item             *Item  `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	Quantity         int    `json:"quantity"`         // отгружено
+	ReceivedQuantity int    `json:"receivedQuantity"` // принято
+	Discrepancy      int    `json:"discrepancy"`      // Quantity - ReceivedQuantity после приёмки
+	DiscrepancyNote  string `json:"discrepancyNote"`  // почему не сошлось
+}
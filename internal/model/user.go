@@ -1,7 +1,26 @@
 package model
 
+// Роли пользователей
+const (
+	RoleAdmin   = "admin"   // всё, включая управление пользователями
+	RoleManager = "manager" // товары, цены, остатки, отчёты
+	RoleCashier = "cashier" // продажи и возвраты
+	RoleViewer  = "viewer"  // только просмотр
+)
+
 type User struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
 	Username string `gorm:"unique" json:"username"`
-	Password string `json:"password"`
+	Password string `json:"password,omitempty"`
+	Role     string `gorm:"default:viewer" json:"role"`
+	Disabled bool   `json:"disabled"` // отключённый пользователь не может войти
+}
+
+// ValidRole проверяет, что роль из известного списка
+func ValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleManager, RoleCashier, RoleViewer:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,12 @@
+package model
+
+// Vehicle — автомобиль (марка, модель, поколение, годы, двигатель) для подбора запчастей
+type Vehicle struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	Make       string `gorm:"index" json:"make"`  // марка
+	Model      string `gorm:"index" json:"model"` // модель
+	Generation string `json:"generation"`         // поколение / кузов
+	YearFrom   int    `json:"yearFrom"`
+	YearTo     int    `json:"yearTo"` // 0 — выпускается по сей день
+	Engine     string `json:"engine"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Warehouse — склад или точка: магазин, подсобка, филиал
+type Warehouse struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `json:"name"`
+	Address   string     `json:"address"`
+	IsDefault bool       `json:"isDefault"` // основной склад: отсюда продаём, если место не указано
+	CreatedAt time.Time  `json:"createdAt"`
+	Locations []Location `gorm:"foreignKey:WarehouseID" json:"locations,omitempty"`
+}
+
+// Location — место хранения на складе: стеллаж, полка, ячейка
+type Location struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	WarehouseID uint       `gorm:"index" json:"warehouseId"`
+	Warehouse   *Warehouse `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	Code        string     `json:"code"` // например, A-01-3
+	Name        string     `json:"name"`
+	IsDefault   bool       `json:"isDefault"` // основное место склада
+}
+
+// ItemStock — сколько товара лежит в месте хранения.
+// Сумма Quantity по товару равна Item.Stock.
+type ItemStock struct {
+	ID         uint     `gorm:"primaryKey" json:"-"`
+	ItemID     uint     `gorm:"uniqueIndex:idx_item_stocks_item_location" json:"itemId"`
+	Item       *Item    `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	LocationID uint     `gorm:"uniqueIndex:idx_item_stocks_item_location;index" json:"locationId"`
+	Location   Location `gorm:"foreignKey:LocationID" json:"location"`
+	Quantity   int      `json:"quantity"`
+}
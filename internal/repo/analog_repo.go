@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// глубина поиска аналогов по цепочке: аналог аналога тоже аналог
+const maxAnalogDepth = 3
+
+type AnalogRepository struct {
+	DB *gorm.DB
+}
+
+func NewAnalogRepository(db *gorm.DB) *AnalogRepository {
+	return &AnalogRepository{DB: db}
+}
+
+// prepareCrossReference нормализует бренды и номера перед сохранением
+func prepareCrossReference(ref *model.CrossReference) error {
+	ref.Brand = strings.ToUpper(strings.TrimSpace(ref.Brand))
+	ref.AnalogBrand = strings.ToUpper(strings.TrimSpace(ref.AnalogBrand))
+	ref.PartNumber = strings.TrimSpace(ref.PartNumber)
+	ref.AnalogPartNumber = strings.TrimSpace(ref.AnalogPartNumber)
+	ref.PartNumberNorm = normalizePartNumber(ref.PartNumber)
+	ref.AnalogPartNumberNorm = normalizePartNumber(ref.AnalogPartNumber)
+
+	if ref.PartNumberNorm == "" || ref.AnalogPartNumberNorm == "" {
+		return fmt.Errorf("номер и номер аналога обязательны")
+	}
+	if ref.PartNumberNorm == ref.AnalogPartNumberNorm && ref.Brand == ref.AnalogBrand {
+		return fmt.Errorf("номер не может быть аналогом самого себя")
+	}
+	return nil
+}
+
+func (r *AnalogRepository) CreateCrossReference(ref *model.CrossReference) error {
+	if err := prepareCrossReference(ref); err != nil {
+		return err
+	}
+	return r.DB.Create(ref).Error
+}
+
+func (r *AnalogRepository) DeleteCrossReference(id uint) error {
+	return r.DB.Delete(&model.CrossReference{}, id).Error
+}
+
+// ImportCrossReferences сохраняет список связей. Уже существующие пропускаются,
+// некорректные не сохраняются и возвращаются с ошибкой по индексу в refs.
+func (r *AnalogRepository) ImportCrossReferences(refs []model.CrossReference) (int64, map[int]error, error) {
+	invalid := map[int]error{}
+	valid := make([]model.CrossReference, 0, len(refs))
+	for i := range refs {
+		if err := prepareCrossReference(&refs[i]); err != nil {
+			invalid[i] = err
+			continue
+		}
+		valid = append(valid, refs[i])
+	}
+	if len(valid) == 0 {
+		return 0, invalid, nil
+	}
+
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(valid, 500)
+	return res.RowsAffected, invalid, res.Error
+}
+
+// ListCrossReferences — прямые связи номера в обе стороны
+func (r *AnalogRepository) ListCrossReferences(number string) ([]model.CrossReference, error) {
+	norm := normalizePartNumber(number)
+	var refs []model.CrossReference
+	err := r.DB.Where("part_number_norm = ? OR analog_part_number_norm = ?", norm, norm).
+		Order("id").
+		Find(&refs).Error
+	return refs, err
+}
+
+// ErrEmptyPartNumber — в номере нет ни одной буквы или цифры
+var ErrEmptyPartNumber = errors.New("пустой номер детали")
+
+// analogPart — номер детали конкретного бренда; пустой бренд — любой
+type analogPart struct {
+	brand  string
+	number string // нормализованный
+}
+
+// analogParts собирает все взаимозаменяемые детали, включая исходную.
+// Один и тот же номер у разных брендов — разные детали, поэтому связь
+// учитывается, только если бренд совпадает или у одной из сторон не указан.
+func (r *AnalogRepository) analogParts(brand, number string) ([]analogPart, error) {
+	start := analogPart{
+		brand:  strings.ToUpper(strings.TrimSpace(brand)),
+		number: normalizePartNumber(number),
+	}
+	if start.number == "" {
+		return nil, ErrEmptyPartNumber
+	}
+
+	seen := map[analogPart]bool{start: true}
+	parts := []analogPart{start}
+	frontier := []analogPart{start}
+
+	for depth := 0; depth < maxAnalogDepth && len(frontier) > 0; depth++ {
+		conds := make([]string, 0, 2*len(frontier))
+		args := make([]interface{}, 0, 6*len(frontier))
+		for _, p := range frontier {
+			conds = append(conds,
+				"(part_number_norm = ? AND (? = '' OR brand = '' OR brand = ?))",
+				"(analog_part_number_norm = ? AND (? = '' OR analog_brand = '' OR analog_brand = ?))")
+			args = append(args, p.number, p.brand, p.brand, p.number, p.brand, p.brand)
+		}
+
+		var refs []model.CrossReference
+		err := r.DB.Select("brand", "part_number_norm", "analog_brand", "analog_part_number_norm").
+			Where(strings.Join(conds, " OR "), args...).
+			Find(&refs).Error
+		if err != nil {
+			return nil, err
+		}
+
+		var next []analogPart
+		for _, ref := range refs {
+			for _, p := range []analogPart{
+				{brand: ref.Brand, number: ref.PartNumberNorm},
+				{brand: ref.AnalogBrand, number: ref.AnalogPartNumberNorm},
+			} {
+				if !seen[p] {
+					seen[p] = true
+					parts = append(parts, p)
+					next = append(next, p)
+				}
+			}
+		}
+		frontier = next
+	}
+	return parts, nil
+}
+
+// FindAnalogItems — товары на складе, взаимозаменяемые с деталью (включая её саму).
+// Бренд необязателен: без него исходный номер подходит у любого бренда.
+func (r *AnalogRepository) FindAnalogItems(brand, number string, inStockOnly bool) ([]model.Item, error) {
+	parts, err := r.analogParts(brand, number)
+	if err != nil {
+		return nil, err
+	}
+
+	conds := make([]string, 0, len(parts))
+	args := make([]interface{}, 0, 3*len(parts))
+	for _, p := range parts {
+		conds = append(conds, "("+model.ItemPartNumberNormSQL+" = ? AND (? = '' OR UPPER(TRIM(brand)) = ?))")
+		args = append(args, p.number, p.brand, p.brand)
+	}
+
+	query := withLocations(r.DB.Preload("Images")).Where(strings.Join(conds, " OR "), args...)
+	if inStockOnly {
+		query = query.Where("stock > 0")
+	}
+
+	var items []model.Item
+	err = query.Order("stock desc, price").Find(&items).Error
+	return items, err
+}
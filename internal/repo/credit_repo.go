@@ -0,0 +1,217 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-backend/internal/model"
+	"warehouse-backend/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCreditNeedsCustomer — в долг продаём только покупателю из справочника
+var ErrCreditNeedsCustomer = errors.New("в долг можно продать только покупателю из справочника")
+
+// CreditLimitError — после продажи долг покупателя превысит его кредитный лимит
+type CreditLimitError struct {
+	CustomerID uint
+	Name       string
+	Limit      int
+	Debt       int // текущий долг
+	Requested  int // сколько добавится
+}
+
+func (e *CreditLimitError) Error() string {
+	return fmt.Sprintf("превышен кредитный лимит покупателя %s: лимит %d, долг %d, в долг ещё %d",
+		e.Name, e.Limit, e.Debt, e.Requested)
+}
+
+// orderDueSQL — долг по чеку
+const orderDueSQL = "(orders.total_price - orders.refunded_amount - orders.paid_amount)"
+
+// customerDebt — сколько покупатель должен по всем чекам
+func customerDebt(tx *gorm.DB, customerID uint) (int, error) {
+	var debt int
+	err := tx.Model(&model.Order{}).
+		Select("COALESCE(SUM("+orderDueSQL+"), 0)").
+		Where("customer_id = ? AND payment_status <> ?", customerID, model.PaymentPaid).
+		Scan(&debt).Error
+	return debt, err
+}
+
+// checkCredit проверяет, можно ли записать покупателю ещё amount долга.
+// Строка покупателя блокируется, чтобы одновременные продажи в долг
+// не обошли лимит.
+func checkCredit(tx *gorm.DB, customer *model.Customer, amount int) error {
+	if customer == nil {
+		return ErrCreditNeedsCustomer
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(customer, customer.ID).Error; err != nil {
+		return err
+	}
+	debt, err := customerDebt(tx, customer.ID)
+	if err != nil {
+		return err
+	}
+	if debt+amount > customer.CreditLimit {
+		return &CreditLimitError{
+			CustomerID: customer.ID,
+			Name:       customer.Name,
+			Limit:      customer.CreditLimit,
+			Debt:       debt,
+			Requested:  amount,
+		}
+	}
+	return nil
+}
+
+// PaymentRequest — оплата долга. Без OrderID сумма гасит чеки по порядку, начиная со старых.
+type PaymentRequest struct {
+	OrderID *uint  `json:"orderId"`
+	Amount  int    `json:"amount"`
+	Note    string `json:"note"`
+	UserID  *uint  `json:"-"`
+}
+
+// RecordPayment принимает оплату долга от покупателя
+func (r *CustomerRepository) RecordPayment(customerID uint, req PaymentRequest) (*model.Payment, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("сумма оплаты должна быть больше нуля")
+	}
+
+	var payment *model.Payment
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var customer model.Customer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerID).Error; err != nil {
+			return err
+		}
+
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_id = ? AND payment_status <> ?", customerID, model.PaymentPaid)
+		if req.OrderID != nil {
+			query = query.Where("id = ?", *req.OrderID)
+		}
+		var orders []model.Order
+		if err := query.Order("sold_at, id").Find(&orders).Error; err != nil {
+			return err
+		}
+
+		debt := 0
+		for i := range orders {
+			debt += orders[i].Due()
+		}
+		if req.OrderID != nil && len(orders) == 0 {
+			return fmt.Errorf("у покупателя нет неоплаченного чека %d", *req.OrderID)
+		}
+		if req.Amount > debt {
+			return fmt.Errorf("сумма оплаты больше долга (%d)", debt)
+		}
+
+		left := req.Amount
+		for i := range orders {
+			if left == 0 {
+				break
+			}
+			order := &orders[i]
+			pay := min(left, order.Due())
+			order.PaidAmount += pay
+			order.UpdatePaymentStatus()
+			left -= pay
+			err := tx.Model(order).Updates(map[string]interface{}{
+				"paid_amount":    order.PaidAmount,
+				"payment_status": order.PaymentStatus,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		payment = &model.Payment{
+			CustomerID: customerID,
+			OrderID:    req.OrderID,
+			Amount:     req.Amount,
+			Note:       req.Note,
+			UserID:     req.UserID,
+			CreatedAt:  time.Now(),
+		}
+		return tx.Create(payment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (r *CustomerRepository) GetPayments(customerID uint) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.DB.Where("customer_id = ?", customerID).Order("created_at desc").Find(&payments).Error
+	return payments, err
+}
+
+// CustomerBalance — долг покупателя и неоплаченные чеки
+type CustomerBalance struct {
+	Debt        int           `json:"debt"`
+	CreditLimit int           `json:"creditLimit"`
+	Available   int           `json:"available"` // сколько ещё можно взять в долг
+	Orders      []model.Order `json:"orders"`    // неоплаченные чеки, старые первыми
+}
+
+func (r *CustomerRepository) CustomerBalance(customerID uint) (*CustomerBalance, error) {
+	var customer model.Customer
+	if err := r.DB.First(&customer, customerID).Error; err != nil {
+		return nil, err
+	}
+
+	balance := CustomerBalance{CreditLimit: customer.CreditLimit}
+	err := r.DB.Where("customer_id = ? AND payment_status <> ?", customerID, model.PaymentPaid).
+		Order("sold_at, id").
+		Find(&balance.Orders).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range balance.Orders {
+		balance.Debt += balance.Orders[i].Due()
+	}
+	balance.Available = max(customer.CreditLimit-balance.Debt, 0)
+	return &balance, nil
+}
+
+// AgingRow — долг покупателя по сроку: сколько дней прошло с продажи
+type AgingRow struct {
+	CustomerID  uint       `json:"customerId"`
+	Name        string     `json:"name"`
+	Phone       string     `json:"phone"`
+	CreditLimit int        `json:"creditLimit"`
+	Days0To30   int        `gorm:"column:days_0_30" json:"days0to30"`
+	Days31To60  int        `gorm:"column:days_31_60" json:"days31to60"`
+	Days61To90  int        `gorm:"column:days_61_90" json:"days61to90"`
+	Over90      int        `gorm:"column:over_90" json:"over90"`
+	Total       int        `json:"total"`
+	OldestSale  *time.Time `json:"oldestSale"` // самый старый неоплаченный чек
+}
+
+// AgingReport — непогашенные долги по покупателям с разбивкой по срокам.
+// Дни считаются по календарю магазина: сегодняшний чек — 0 дней.
+func (r *CustomerRepository) AgingReport(now time.Time) ([]AgingRow, error) {
+	today := utils.StartOfDay(now)
+	day := func(n int) time.Time { return today.AddDate(0, 0, -n) }
+
+	var rows []AgingRow
+	err := r.DB.Table("orders").
+		Select(`customers.id AS customer_id, customers.name, customers.phone, customers.credit_limit,
+			COALESCE(SUM(CASE WHEN orders.sold_at >= ? THEN `+orderDueSQL+` END), 0) AS days_0_30,
+			COALESCE(SUM(CASE WHEN orders.sold_at < ? AND orders.sold_at >= ? THEN `+orderDueSQL+` END), 0) AS days_31_60,
+			COALESCE(SUM(CASE WHEN orders.sold_at < ? AND orders.sold_at >= ? THEN `+orderDueSQL+` END), 0) AS days_61_90,
+			COALESCE(SUM(CASE WHEN orders.sold_at < ? THEN `+orderDueSQL+` END), 0) AS over_90,
+			SUM(`+orderDueSQL+`) AS total,
+			MIN(orders.sold_at) AS oldest_sale`,
+			day(30), day(30), day(60), day(60), day(90), day(90)).
+		Joins("JOIN customers ON orders.customer_id = customers.id").
+		Where("orders.payment_status <> ?", model.PaymentPaid).
+		Group("customers.id").
+		Order("total DESC").
+		Scan(&rows).Error
+	return rows, err
+}
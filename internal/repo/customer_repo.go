@@ -0,0 +1,179 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CustomerRepository struct {
+	DB *gorm.DB
+}
+
+func NewCustomerRepository(db *gorm.DB) *CustomerRepository {
+	return &CustomerRepository{DB: db}
+}
+
+// CustomerStats — итоги по покупателю за всё время, за вычетом возвратов
+type CustomerStats struct {
+	Orders        int64      `json:"orders"`        // чеков
+	Quantity      int        `json:"quantity"`      // куплено штук
+	LifetimeValue int        `json:"lifetimeValue"` // сумма покупок
+	FirstPurchase *time.Time `json:"firstPurchase"`
+	LastPurchase  *time.Time `json:"lastPurchase"`
+	Debt          int        `json:"debt" gorm:"-"` // текущий долг
+}
+
+func validateCustomer(c *model.Customer) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("имя покупателя обязательно")
+	}
+	switch c.Type {
+	case "":
+		c.Type = model.CustomerRetail
+	case model.CustomerRetail, model.CustomerWholesale:
+	default:
+		return fmt.Errorf("неизвестный тип покупателя: %s", c.Type)
+	}
+	switch c.PriceTier {
+	case "":
+		// оптовому покупателю по умолчанию оптовая цена
+		c.PriceTier = model.PriceTierRetail
+		if c.Type == model.CustomerWholesale {
+			c.PriceTier = model.PriceTierWholesale
+		}
+	case model.PriceTierRetail, model.PriceTierWholesale:
+	default:
+		return fmt.Errorf("неизвестный уровень цены: %s", c.PriceTier)
+	}
+	if c.CreditLimit < 0 {
+		return fmt.Errorf("кредитный лимит не может быть отрицательным")
+	}
+	c.Phone = strings.TrimSpace(c.Phone)
+	c.NameKey = model.CustomerNameKey(c.Name)
+	return nil
+}
+
+// checkDuplicate не даёт завести второго покупателя с тем же именем и телефоном
+func (r *CustomerRepository) checkDuplicate(c *model.Customer) error {
+	var count int64
+	err := r.DB.Model(&model.Customer{}).
+		Where("name_key = ? AND phone = ? AND id <> ?", c.NameKey, c.Phone, c.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("покупатель %q уже есть в справочнике", c.Name)
+	}
+	return nil
+}
+
+func (r *CustomerRepository) CreateCustomer(c *model.Customer) error {
+	if err := validateCustomer(c); err != nil {
+		return err
+	}
+	if err := r.checkDuplicate(c); err != nil {
+		return err
+	}
+	return r.DB.Create(c).Error
+}
+
+func (r *CustomerRepository) UpdateCustomer(id uint, c *model.Customer) (*model.Customer, error) {
+	var existing model.Customer
+	if err := r.DB.First(&existing, id).Error; err != nil {
+		return nil, err
+	}
+	c.ID = existing.ID
+	c.CreatedAt = existing.CreatedAt
+	if err := validateCustomer(c); err != nil {
+		return nil, err
+	}
+	if err := r.checkDuplicate(c); err != nil {
+		return nil, err
+	}
+	if err := r.DB.Save(c).Error; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *CustomerRepository) GetCustomer(id uint) (*model.Customer, error) {
+	var customer model.Customer
+	if err := r.DB.First(&customer, id).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// SearchCustomers ищет по части имени (без учёта регистра) или телефона
+func (r *CustomerRepository) SearchCustomers(q, customerType string, limit int) ([]model.Customer, error) {
+	query := r.DB.Model(&model.Customer{})
+	if key := model.CustomerNameKey(q); key != "" {
+		pattern := "%" + key + "%"
+		query = query.Where("name_key LIKE ? OR phone LIKE ?", pattern, "%"+strings.TrimSpace(q)+"%")
+	}
+	if customerType != "" {
+		query = query.Where("type = ?", customerType)
+	}
+
+	var customers []model.Customer
+	err := query.Order("name").Limit(limit).Find(&customers).Error
+	return customers, err
+}
+
+// CustomerOrders — чеки покупателя, новые первыми
+func (r *CustomerRepository) CustomerOrders(id uint, page, pageSize int) ([]model.Order, int64, error) {
+	var total int64
+	query := r.DB.Model(&model.Order{}).Where("customer_id = ?", id)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []model.Order
+	err := r.DB.Preload("Lines.Item").
+		Where("customer_id = ?", id).
+		Order("sold_at desc").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&orders).Error
+	for i := range orders {
+		netSales(orders[i].Lines)
+	}
+	return orders, total, err
+}
+
+// CustomerLegacySales — старые продажи покупателя, сделанные до появления чеков (без order_id)
+func (r *CustomerRepository) CustomerLegacySales(id uint) ([]model.Sale, error) {
+	var sales []model.Sale
+	err := r.DB.Preload("Item").
+		Where("customer_id = ? AND order_id IS NULL", id).
+		Order("sold_at desc").
+		Find(&sales).Error
+	netSales(sales)
+	return sales, err
+}
+
+func (r *CustomerRepository) CustomerStats(id uint) (*CustomerStats, error) {
+	var stats CustomerStats
+	err := r.DB.Table("sales").
+		// старые продажи без чека считаются каждая отдельной покупкой
+		Select(`COUNT(DISTINCT order_id) + COUNT(*) FILTER (WHERE order_id IS NULL) AS orders,
+			COALESCE(SUM(quantity - returned_quantity), 0) AS quantity,
+			COALESCE(SUM(total_price - refunded_amount), 0) AS lifetime_value,
+			MIN(sold_at) AS first_purchase,
+			MAX(sold_at) AS last_purchase`).
+		Where("customer_id = ?", id).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	if stats.Debt, err = customerDebt(r.DB, id); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
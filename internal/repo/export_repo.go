@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// размер пачки при построчной выгрузке
+const exportBatchSize = 500
+
+// SalesFilter — фильтр продаж по бренду и периоду [From, To)
+type SalesFilter struct {
+	Brand string
+	From  *time.Time
+	To    *time.Time
+}
+
+func (f SalesFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Brand != "" {
+		query = query.Joins("JOIN items ON sales.item_id = items.id").Where("items.brand = ?", f.Brand)
+	}
+	if f.From != nil {
+		query = query.Where("sales.sold_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("sales.sold_at < ?", *f.To)
+	}
+	return query
+}
+
+func (r *ItemRepository) ListSales(f SalesFilter) ([]model.Sale, error) {
+	var sales []model.Sale
+	err := f.apply(r.DB.Model(&model.Sale{})).
+		Preload("Item").
+		Order("sales.sold_at desc").
+		Find(&sales).Error
+	netSales(sales)
+	return sales, err
+}
+
+// exportQuery ограничивает выгрузку limit строками; 0 — без ограничения
+func exportQuery(query *gorm.DB, limit int) *gorm.DB {
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return query
+}
+
+// EachItem проходит по товарам под фильтром пачками, не загружая всё в память.
+// Страница и сортировка фильтра не учитываются — порядок по ID; limit > 0 — не больше limit товаров.
+func (r *ItemRepository) EachItem(f ItemFilter, limit int, fn func(*model.Item) error) error {
+	var batch []model.Item
+	var fnErr error
+	res := exportQuery(f.apply(r.DB.Model(&model.Item{})), limit).FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if fnErr = fn(&batch[i]); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return res.Error
+}
+
+// EachSale проходит по продажам под фильтром пачками (по возрастанию ID);
+// limit > 0 — не больше limit продаж
+func (r *ItemRepository) EachSale(f SalesFilter, limit int, fn func(*model.Sale) error) error {
+	var batch []model.Sale
+	var fnErr error
+	res := exportQuery(f.apply(r.DB.Model(&model.Sale{})), limit).
+		Preload("Item").
+		FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				if fnErr = fn(&batch[i]); fnErr != nil {
+					return fnErr
+				}
+			}
+			return nil
+		})
+	if fnErr != nil {
+		return fnErr
+	}
+	return res.Error
+}
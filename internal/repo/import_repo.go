@@ -0,0 +1,247 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// RowError — ошибка в строке загружаемого файла (строки считаются с 1, включая заголовок)
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ItemImportRow — строка прайса. nil — колонки нет в файле, поле не трогаем.
+type ItemImportRow struct {
+	Row            int
+	PartNumber     string
+	Brand          string
+	Name           *string
+	Model          *string
+	Stock          *int
+	Price          *int
+	WholesalePrice *int
+}
+
+// Действия над строкой импорта
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+)
+
+type ItemImportResult struct {
+	Row        int                    `json:"row"`
+	Action     string                 `json:"action"`
+	ItemID     uint                   `json:"itemId,omitempty"`
+	PartNumber string                 `json:"partNumber"`
+	Brand      string                 `json:"brand"`
+	Changes    map[string]interface{} `json:"changes,omitempty"` // для обновлений: новые значения полей
+}
+
+type ItemImportReport struct {
+	DryRun    bool               `json:"dryRun"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Rows      []ItemImportResult `json:"rows"`
+	Errors    []RowError         `json:"errors"`
+}
+
+// errDryRun откатывает транзакцию пробного импорта
+var errDryRun = errors.New("dry run")
+
+func importKey(partNumber, brand string) string {
+	return normalizePartNumber(partNumber) + "|" + strings.ToUpper(strings.TrimSpace(brand))
+}
+
+// ImportItems создаёт или обновляет товары по паре номер + бренд (номер сравнивается
+// без дефисов и пробелов). Ошибка в строке не останавливает импорт — строка
+// пропускается и попадает в отчёт. В режиме dryRun всё выполняется в транзакции,
+// которая затем откатывается, так что отчёт точно показывает, что изменится.
+func (r *ItemRepository) ImportItems(rows []ItemImportRow, dryRun bool, userID *uint) (*ItemImportReport, error) {
+	report := &ItemImportReport{
+		DryRun: dryRun,
+		Rows:   []ItemImportResult{},
+		Errors: []RowError{},
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := loadImportTargets(tx, rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			// точка сохранения: ошибка в строке не должна обрывать всю транзакцию
+			if err := tx.SavePoint("import_row").Error; err != nil {
+				return err
+			}
+			result, item, err := importRow(tx, row, existing, userID)
+			if err != nil {
+				if err := tx.RollbackTo("import_row").Error; err != nil {
+					return err
+				}
+				report.Errors = append(report.Errors, RowError{Row: row.Row, Error: err.Error()})
+				continue
+			}
+			// в кеш — только после успешной строки: откаченная строка не должна
+			// оставить в нём товар, которого нет, или непроведённые изменения
+			existing[importKey(row.PartNumber, row.Brand)] = item
+
+			switch result.Action {
+			case ImportCreate:
+				report.Created++
+			case ImportUpdate:
+				report.Updated++
+			default:
+				report.Unchanged++
+			}
+			report.Rows = append(report.Rows, *result)
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// loadImportTargets загружает уже существующие товары с номерами из файла
+func loadImportTargets(tx *gorm.DB, rows []ItemImportRow) (map[string]*model.Item, error) {
+	numbers := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if norm := normalizePartNumber(row.PartNumber); norm != "" {
+			numbers = append(numbers, norm)
+		}
+	}
+
+	existing := make(map[string]*model.Item)
+	const chunk = 1000
+	for start := 0; start < len(numbers); start += chunk {
+		end := min(start+chunk, len(numbers))
+		var items []model.Item
+		err := tx.Where(model.ItemPartNumberNormSQL+" IN ?", numbers[start:end]).
+			Order("id").
+			Find(&items).Error
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			key := importKey(items[i].PartNumber, items[i].Brand)
+			if _, ok := existing[key]; !ok {
+				existing[key] = &items[i]
+			}
+		}
+	}
+	return existing, nil
+}
+
+// importRow создаёт или обновляет товар по строке и возвращает его новое состояние.
+// existing не меняется — его обновляет вызывающий, когда строка прошла целиком.
+func importRow(tx *gorm.DB, row ItemImportRow, existing map[string]*model.Item, userID *uint) (*ItemImportResult, *model.Item, error) {
+	if normalizePartNumber(row.PartNumber) == "" {
+		return nil, nil, fmt.Errorf("не указан номер детали")
+	}
+	if strings.TrimSpace(row.Brand) == "" {
+		return nil, nil, fmt.Errorf("не указан бренд")
+	}
+	for name, v := range map[string]*int{"stock": row.Stock, "price": row.Price, "wholesalePrice": row.WholesalePrice} {
+		if v != nil && *v < 0 {
+			return nil, nil, fmt.Errorf("%s не может быть отрицательным", name)
+		}
+	}
+
+	result := &ItemImportResult{Row: row.Row, PartNumber: row.PartNumber, Brand: row.Brand}
+	key := importKey(row.PartNumber, row.Brand)
+	cached, ok := existing[key]
+
+	if !ok {
+		if row.Name == nil || strings.TrimSpace(*row.Name) == "" {
+			return nil, nil, fmt.Errorf("для нового товара нужно наименование")
+		}
+		item := &model.Item{
+			Name:       strings.TrimSpace(*row.Name),
+			PartNumber: strings.TrimSpace(row.PartNumber),
+			Brand:      strings.TrimSpace(row.Brand),
+		}
+		if row.Model != nil {
+			item.Model = *row.Model
+		}
+		if row.Price != nil {
+			item.Price = *row.Price
+		}
+		if row.WholesalePrice != nil {
+			item.WholesalePrice = *row.WholesalePrice
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return nil, nil, err
+		}
+		if row.Stock != nil && *row.Stock > 0 {
+			updated, err := applyStockMovement(tx, &model.StockMovement{
+				ItemID:   item.ID,
+				Type:     model.MovementReceipt,
+				Quantity: *row.Stock,
+				UserID:   userID,
+				Reason:   "импорт",
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			item.Stock = updated.Stock
+		}
+
+		result.Action = ImportCreate
+		result.ItemID = item.ID
+		return result, item, nil
+	}
+
+	copied := *cached
+	item := &copied
+
+	// updates — по колонкам БД, changes — те же поля для отчёта в JSON-именах
+	updates := map[string]interface{}{}
+	changes := map[string]interface{}{}
+	if row.Name != nil && *row.Name != "" && *row.Name != item.Name {
+		updates["name"], changes["name"] = *row.Name, *row.Name
+	}
+	if row.Model != nil && *row.Model != item.Model {
+		updates["model"], changes["model"] = *row.Model, *row.Model
+	}
+	if row.Price != nil && *row.Price != item.Price {
+		updates["price"], changes["price"] = *row.Price, *row.Price
+	}
+	if row.WholesalePrice != nil && *row.WholesalePrice != item.WholesalePrice {
+		updates["wholesale_price"], changes["wholesalePrice"] = *row.WholesalePrice, *row.WholesalePrice
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(item).Updates(updates).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	if row.Stock != nil && *row.Stock != item.Stock {
+		if err := setStock(tx, item.ID, nil, *row.Stock, userID, "импорт"); err != nil {
+			return nil, nil, err
+		}
+		item.Stock = *row.Stock
+		changes["stock"] = *row.Stock
+	}
+
+	result.ItemID = item.ID
+	if len(changes) == 0 {
+		result.Action = ImportUnchanged
+		return result, item, nil
+	}
+	result.Action = ImportUpdate
+	result.Changes = changes
+	return result, item, nil
+}
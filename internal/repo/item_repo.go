@@ -2,9 +2,11 @@ package repo
 
 import (
 	"fmt"
-	"gorm.io/gorm"
 	"time"
 	"warehouse-backend/internal/model"
+	"warehouse-backend/pkg/utils"
+
+	"gorm.io/gorm"
 )
 
 type ItemRepository struct {
@@ -19,99 +21,173 @@ var allowedFields = map[string]string{
 	"model":          "model",
 	"partNumber":     "part_number",
 	"wholesalePrice": "wholesale_price",
+	"minStock":       "min_stock",
+	"reorderQty":     "reorder_qty",
 }
 
 func NewItemRepository(db *gorm.DB) *ItemRepository {
 	return &ItemRepository{DB: db}
 }
 
-func (r *ItemRepository) AddItem(item *model.Item) error {
-	return r.DB.Session(&gorm.Session{FullSaveAssociations: true}).Create(item).Error
+func (r *ItemRepository) AddItem(item *model.Item, userID *uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// начальный остаток проводим через журнал как приход на основное место
+		stock := item.Stock
+		item.Stock = 0
+		item.Locations = nil
+		if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Create(item).Error; err != nil {
+			return err
+		}
+		if stock == 0 {
+			return nil
+		}
+
+		updated, err := applyStockMovement(tx, &model.StockMovement{
+			ItemID:   item.ID,
+			Type:     model.MovementReceipt,
+			Quantity: stock,
+			UserID:   userID,
+			Reason:   "начальный остаток",
+		})
+		if err != nil {
+			return err
+		}
+		item.Stock = updated.Stock
+		return nil
+	})
 }
 
-func (r *ItemRepository) UpdateStock(id uint, quantity int) (*model.Item, error) {
-	var item model.Item
-	if err := r.DB.First(&item, id).Error; err != nil {
-		return nil, err
-	}
-	if item.Stock < quantity {
-		return nil, gorm.ErrInvalidData
-	}
-	item.Stock -= quantity
-	if err := r.DB.Save(&item).Error; err != nil {
-		return nil, err
-	}
-	return &item, nil
+// withLocations подгружает, где лежит товар: места хранения с ненулевым остатком
+func withLocations(query *gorm.DB) *gorm.DB {
+	return query.Preload("Locations", func(db *gorm.DB) *gorm.DB {
+		return db.Where("quantity <> 0").Order("location_id").Preload("Location.Warehouse")
+	})
 }
 
-func (r *ItemRepository) GetAllItems() ([]model.Item, error) {
-	var items []model.Item
-	err := r.DB.Preload("Images").Find(&items).Error
-	return items, err
+// ItemFilter — фильтры, сортировка и страница для списка товаров
+type ItemFilter struct {
+	Brand    string
+	Model    string
+	MinPrice *int
+	MaxPrice *int
+	InStock  *bool // true — только в наличии, false — только закончившиеся
+	Sort     string
+	Desc     bool
+	Page     int // с 1
+	PageSize int // 0 — без страниц, весь список
 }
-func (r *ItemRepository) GetItemsByBrand(brand string) ([]model.Item, error) {
-	var items []model.Item
-	err := r.DB.Preload("Images").Where("brand = ?", brand).Find(&items).Error
-	return items, err
+
+// поля, по которым разрешена сортировка
+var itemSortColumns = map[string]string{
+	"id":    "id",
+	"name":  "name",
+	"price": "price",
+	"stock": "stock",
 }
 
-func (r *ItemRepository) MakeSale(itemID uint, quantity int, customer string) (*model.Sale, error) {
-	var item model.Item
-	if err := r.DB.First(&item, itemID).Error; err != nil {
-		return nil, err
+func (f ItemFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Brand != "" {
+		query = query.Where("brand = ?", f.Brand)
+	}
+	if f.Model != "" {
+		query = query.Where("model = ?", f.Model)
 	}
+	if f.MinPrice != nil {
+		query = query.Where("price >= ?", *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		query = query.Where("price <= ?", *f.MaxPrice)
+	}
+	if f.InStock != nil {
+		if *f.InStock {
+			query = query.Where("stock > 0")
+		} else {
+			query = query.Where("stock <= 0")
+		}
+	}
+	return query
+}
 
-	if item.Stock < quantity {
-		return nil, fmt.Errorf("недостаточно товара на складе")
+// ListItems возвращает страницу товаров (или все, если PageSize 0) и общее число подходящих под фильтр
+func (r *ItemRepository) ListItems(f ItemFilter) ([]model.Item, int64, error) {
+	var total int64
+	if err := f.apply(r.DB.Model(&model.Item{})).Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	// уменьшаем количество
-	item.Stock -= quantity
-	if err := r.DB.Save(&item).Error; err != nil {
-		return nil, err
+	column, ok := itemSortColumns[f.Sort]
+	if !ok {
+		column = "id"
+	}
+	order := column
+	if f.Desc {
+		order += " desc"
 	}
 
-	// создаём продажу
-	sale := model.Sale{
-		ItemID:     itemID,
-		Quantity:   quantity,
-		TotalPrice: item.Price * quantity,
-		Customer:   customer,
-		SoldAt:     time.Now(),
+	query := f.apply(withLocations(r.DB.Preload("Images"))).
+		Order(order).
+		Order("id") // стабильный порядок при одинаковых значениях
+	if f.PageSize > 0 {
+		query = query.Offset((f.Page - 1) * f.PageSize).Limit(f.PageSize)
 	}
 
-	if err := r.DB.Create(&sale).Error; err != nil {
+	var items []model.Item
+	err := query.Find(&items).Error
+	return items, total, err
+}
+
+// MakeSale продаёт один товар — это чек из одной позиции
+func (r *ItemRepository) MakeSale(req OrderRequest) (*model.Sale, error) {
+	if len(req.Lines) != 1 {
+		return nil, fmt.Errorf("продажа должна содержать одну позицию")
+	}
+	order, err := r.MakeOrder(req)
+	if err != nil {
 		return nil, err
 	}
-
-	return &sale, nil
+	return &order.Lines[0], nil
 }
 
 func (r *ItemRepository) GetTodaySales() ([]model.Sale, error) {
 	var sales []model.Sale
 
-	today := time.Now().Truncate(24 * time.Hour) // начало дня (00:00)
+	now := time.Now()
+	// начало и конец дня по времени магазина
 	err := r.DB.Preload("Item").
-		Where("sold_at >= ?", today).
+		Where("sold_at >= ? AND sold_at < ?", utils.StartOfDay(now), utils.NextDay(now)).
 		Order("sold_at desc").
 		Find(&sales).Error
+	netSales(sales)
 
 	return sales, err
 }
 
+// netSales считает по каждой продаже количество и сумму за вычетом возвратов
+func netSales(sales []model.Sale) {
+	for i := range sales {
+		sales[i].NetQuantity = sales[i].Quantity - sales[i].ReturnedQuantity
+		sales[i].NetTotal = sales[i].TotalPrice - sales[i].RefundedAmount
+	}
+}
+
 func (r *ItemRepository) GetTop5BestSellers() ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
+	now := time.Now()
+	return r.GetTopBestSellers(5, now.AddDate(0, 0, -7), now)
+}
 
-	oneWeekAgo := time.Now().AddDate(0, 0, -7)
+// GetTopBestSellers — самые продаваемые товары за период [from, to) за вычетом возвратов
+func (r *ItemRepository) GetTopBestSellers(limit int, from, to time.Time) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
 
 	// Используем raw SQL с join, group by и order
 	rows, err := r.DB.Table("sales").
-		Select("items.name, items.part_number, SUM(sales.quantity) as total_sold").
+		Select("items.name, items.part_number, SUM(sales.quantity - sales.returned_quantity) as total_sold").
 		Joins("JOIN items ON sales.item_id = items.id").
-		Where("sales.sold_at >= ?", oneWeekAgo).
+		Where("sales.sold_at >= ? AND sales.sold_at < ?", from, to).
 		Group("items.id, items.name, items.part_number").
+		Having("SUM(sales.quantity - sales.returned_quantity) > 0").
 		Order("total_sold DESC").
-		Limit(5).
+		Limit(limit).
 		Rows()
 	if err != nil {
 		return nil, err
@@ -134,18 +210,7 @@ func (r *ItemRepository) GetTop5BestSellers() ([]map[string]interface{}, error)
 	return results, nil
 }
 
-func (r *ItemRepository) GetSalesByBrand(brand string) ([]model.Sale, error) {
-	var sales []model.Sale
-	err := r.DB.
-		Joins("JOIN items ON sales.item_id = items.id").
-		Where("items.brand = ?", brand).
-		Preload("Item").
-		Order("sales.sold_at DESC").
-		Find(&sales).Error
-	return sales, err
-}
-
-func (r *ItemRepository) UpdateItem(id uint, updates map[string]interface{}) (*model.Item, error) {
+func (r *ItemRepository) UpdateItem(id uint, updates map[string]interface{}, userID *uint) (*model.Item, error) {
 	var item model.Item
 
 	// Найти товар
@@ -164,18 +229,31 @@ func (r *ItemRepository) UpdateItem(id uint, updates map[string]interface{}) (*m
 		delete(updates, "images") // чтобы GORM не ругался на []struct
 	}
 
-	// Обновить остальные поля
-	if err := r.DB.Model(&item).Updates(updates).Error; err != nil {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// Остаток меняем только через журнал — разница проводится как корректировка
+		// остатка в месте хранения stock_location_id (без него — основное место)
+		locationID, _ := updates["stock_location_id"].(*uint)
+		delete(updates, "stock_location_id")
+		if v, ok := updates["stock"]; ok {
+			delete(updates, "stock")
+			if stock, ok := v.(int); ok {
+				if err := setStock(tx, item.ID, locationID, stock, userID, "ручная корректировка"); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Обновить остальные поля
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&item).Updates(updates).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Вернуть с изображениями
-	r.DB.Preload("Images").First(&item, id)
+	// Вернуть с изображениями и местами хранения
+	withLocations(r.DB.Preload("Images")).First(&item, id)
 	return &item, nil
 }
-
-func (r *ItemRepository) GetAllSales() ([]model.Sale, error) {
-	var sales []model.Sale
-	err := r.DB.Preload("Item").Order("sold_at desc").Find(&sales).Error
-	return sales, err
-}
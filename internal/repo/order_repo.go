@@ -0,0 +1,227 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SaleLine — одна позиция в чеке при оформлении продажи
+type SaleLine struct {
+	ItemID        uint   `json:"itemId"`
+	Quantity      int    `json:"quantity"`
+	PriceTier     string `json:"priceTier"`     // retail (по умолчанию) или wholesale
+	UnitPrice     *int   `json:"unitPrice"`     // ручная цена за штуку
+	DiscountType  string `json:"discountType"`  // percent или fixed
+	DiscountValue int    `json:"discountValue"` // процент или сумма на позицию
+	LocationID    *uint  `json:"locationId"`    // откуда продаём; пусто — основное место
+}
+
+// OrderRequest — данные для оформления чека
+type OrderRequest struct {
+	CustomerID *uint      `json:"customerId"` // покупатель из справочника
+	Customer   string     `json:"customer"`   // или просто имя
+	Lines      []SaleLine `json:"lines"`
+	UserID     *uint      `json:"-"`          // кассир, заполняется из токена
+	PaidAmount *int       `json:"paidAmount"` // заплачено сразу; пусто — вся сумма, меньше — остаток в долг
+
+	AllowPriceOverride bool `json:"-"` // может ли кассир ставить цену вручную
+}
+
+// MakeOrder оформляет чек целиком: либо списываются все позиции, либо ни одна
+func (r *ItemRepository) MakeOrder(req OrderRequest) (*model.Order, error) {
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("чек не содержит позиций")
+	}
+
+	now := time.Now()
+	order := model.Order{
+		SoldAt:   now,
+		Customer: req.Customer,
+		UserID:   req.UserID,
+	}
+
+	var alerts []model.StockAlert
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		customer, err := orderCustomer(tx, req)
+		if err != nil {
+			return err
+		}
+		if customer != nil {
+			order.CustomerID = &customer.ID
+			order.Customer = customer.Name
+		}
+
+		itemIDs := make([]uint, 0, len(req.Lines))
+		for _, line := range req.Lines {
+			itemIDs = append(itemIDs, line.ItemID)
+		}
+		if err := lockItems(tx, itemIDs); err != nil {
+			return err
+		}
+
+		for _, line := range req.Lines {
+			if line.Quantity <= 0 {
+				return fmt.Errorf("количество должно быть больше нуля")
+			}
+			// уровень цены покупателя утвердил менеджер — применяем его при любом кассире
+			customerTier := false
+			if line.PriceTier == "" && customer != nil {
+				line.PriceTier = customer.PriceTier
+				customerTier = true
+			}
+
+			locationID, err := resolveLocation(tx, line.LocationID)
+			if err != nil {
+				return err
+			}
+			item, err := changeStock(tx, line.ItemID, locationID, -line.Quantity)
+			if err != nil {
+				return err
+			}
+			// уведомляем, только когда продажа перешла через точку заказа;
+			// товар в пути между местами хранения считаем имеющимся
+			if item.MinStock > 0 && item.Stock < item.MinStock {
+				inTransit, err := inTransitQuantity(tx, item.ID)
+				if err != nil {
+					return err
+				}
+				if stock := item.Stock + inTransit; stock < item.MinStock && stock+line.Quantity >= item.MinStock {
+					alerts = append(alerts, model.StockAlert{
+						ItemID:    item.ID,
+						Stock:     item.Stock,
+						MinStock:  item.MinStock,
+						CreatedAt: now,
+					})
+				}
+			}
+
+			price, err := priceSaleLine(tx, item, line, req.AllowPriceOverride, customerTier, now)
+			if err != nil {
+				return err
+			}
+
+			order.TotalPrice += price.total
+			order.Lines = append(order.Lines, model.Sale{
+				ItemID:          line.ItemID,
+				Quantity:        line.Quantity,
+				TotalPrice:      price.total,
+				Customer:        order.Customer,
+				CustomerID:      order.CustomerID,
+				SoldAt:          now,
+				UserID:          req.UserID,
+				LocationID:      &locationID,
+				UnitPrice:       price.unitPrice,
+				UnitCost:        item.LastCost,
+				PriceTier:       price.tier,
+				ListPrice:       price.listPrice,
+				PriceOverridden: price.overridden,
+				DiscountAmount:  price.discount,
+				PromotionID:     price.promotionID,
+				NetQuantity:     line.Quantity,
+				NetTotal:        price.total,
+			})
+		}
+
+		order.PaidAmount = order.TotalPrice
+		if req.PaidAmount != nil {
+			if *req.PaidAmount < 0 || *req.PaidAmount > order.TotalPrice {
+				return fmt.Errorf("оплата должна быть от 0 до %d", order.TotalPrice)
+			}
+			order.PaidAmount = *req.PaidAmount
+		}
+		order.UpdatePaymentStatus()
+		if due := order.Due(); due > 0 {
+			if err := checkCredit(tx, customer, due); err != nil {
+				return err
+			}
+		}
+
+		// позиции сохраняются вместе с чеком
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		// остаток уже списан выше — здесь только записи в журнал
+		for i := range order.Lines {
+			sale := &order.Lines[i]
+			if err := tx.Create(&model.StockMovement{
+				ItemID:     sale.ItemID,
+				Type:       model.MovementSale,
+				Quantity:   -sale.Quantity,
+				LocationID: sale.LocationID,
+				UserID:     req.UserID,
+				SaleID:     &sale.ID,
+				CreatedAt:  now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range alerts {
+			alerts[i].OrderID = &order.ID
+		}
+		if len(alerts) > 0 {
+			if err := tx.Create(&alerts).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, alert := range alerts {
+		log.Printf("⚠️ Остаток товара %d опустился до %d (точка заказа %d)", alert.ItemID, alert.Stock, alert.MinStock)
+	}
+	return &order, nil
+}
+
+// orderCustomer — покупатель чека: по ID или, если указано только имя,
+// единственный покупатель с таким именем из справочника
+func orderCustomer(tx *gorm.DB, req OrderRequest) (*model.Customer, error) {
+	var customer model.Customer
+	if req.CustomerID != nil {
+		if err := tx.First(&customer, *req.CustomerID).Error; err != nil {
+			return nil, err
+		}
+		return &customer, nil
+	}
+
+	key := model.CustomerNameKey(req.Customer)
+	if key == "" {
+		return nil, nil
+	}
+	var matches []model.Customer
+	if err := tx.Where("name_key = ?", key).Limit(2).Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	if len(matches) != 1 {
+		return nil, nil // нет такого или неоднозначно — остаётся просто имя
+	}
+	return &matches[0], nil
+}
+
+func (r *ItemRepository) GetOrder(id uint) (*model.Order, error) {
+	var order model.Order
+	err := r.DB.Preload("Lines.Item").First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	netSales(order.Lines)
+	return &order, nil
+}
+
+func (r *ItemRepository) GetOrders() ([]model.Order, error) {
+	var orders []model.Order
+	err := r.DB.Preload("Lines.Item").Order("sold_at desc").Find(&orders).Error
+	for i := range orders {
+		netSales(orders[i].Lines)
+	}
+	return orders, err
+}
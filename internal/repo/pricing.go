@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Типы ручной скидки
+const (
+	DiscountPercent = "percent" // процент от суммы позиции
+	DiscountFixed   = "fixed"   // сумма на всю позицию
+)
+
+// ErrPriceOverrideForbidden — оптовую или ручную цену и скидку сверх лимита может давать только администратор или менеджер
+var ErrPriceOverrideForbidden = errors.New("нет прав менять цену вручную")
+
+// cashierMaxDiscountPercent — наибольшая ручная скидка кассира, % от суммы позиции
+var cashierMaxDiscountPercent = 0
+
+// LoadPricingConfig читает CASHIER_MAX_DISCOUNT_PERCENT (0–100).
+// Без настройки кассир ручных скидок не даёт.
+func LoadPricingConfig() error {
+	value := os.Getenv("CASHIER_MAX_DISCOUNT_PERCENT")
+	if value == "" {
+		cashierMaxDiscountPercent = 0
+		return nil
+	}
+	percent, err := strconv.Atoi(value)
+	if err != nil || percent < 0 || percent > 100 {
+		return fmt.Errorf("invalid CASHIER_MAX_DISCOUNT_PERCENT: expected a number from 0 to 100, got %q", value)
+	}
+	cashierMaxDiscountPercent = percent
+	return nil
+}
+
+// linePrice — расчёт цены позиции
+type linePrice struct {
+	tier        string
+	listPrice   int
+	unitPrice   int
+	overridden  bool
+	discount    int
+	promotionID *uint
+	total       int
+}
+
+// activePromotion — самая выгодная действующая акция на товар или его бренд
+func activePromotion(tx *gorm.DB, item *model.Item, now time.Time) (*model.Promotion, error) {
+	var promo model.Promotion
+	err := tx.Where("active AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Where("item_id = ? OR (item_id IS NULL AND brand <> '' AND brand = ?)", item.ID, item.Brand).
+		Order("percent desc").
+		First(&promo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// priceSaleLine считает цену позиции: уровень цены (розница/опт), ручная цена,
+// ручная скидка и акция. Ручная скидка и акция не суммируются — берётся бо́льшая.
+// Акции действуют только на розничную цену без ручной правки. Без права менять цену
+// скидка ограничена CASHIER_MAX_DISCOUNT_PERCENT, а оптовый уровень доступен, только
+// если он задан покупателю в справочнике (customerTier).
+func priceSaleLine(tx *gorm.DB, item *model.Item, line SaleLine, allowOverride, customerTier bool, now time.Time) (*linePrice, error) {
+	p := &linePrice{tier: line.PriceTier, listPrice: item.Price}
+
+	switch p.tier {
+	case "", model.PriceTierRetail:
+		p.tier = model.PriceTierRetail
+		p.unitPrice = item.Price
+	case model.PriceTierWholesale:
+		if !allowOverride && !customerTier {
+			return nil, ErrPriceOverrideForbidden
+		}
+		if item.WholesalePrice <= 0 {
+			return nil, fmt.Errorf("у товара %d не задана оптовая цена", item.ID)
+		}
+		p.unitPrice = item.WholesalePrice
+	default:
+		return nil, fmt.Errorf("неизвестный уровень цены: %s", line.PriceTier)
+	}
+
+	if line.UnitPrice != nil {
+		if !allowOverride {
+			return nil, ErrPriceOverrideForbidden
+		}
+		if *line.UnitPrice < 0 {
+			return nil, fmt.Errorf("цена не может быть отрицательной")
+		}
+		p.unitPrice = *line.UnitPrice
+		p.overridden = true
+	}
+
+	gross := p.unitPrice * line.Quantity
+
+	switch line.DiscountType {
+	case "":
+		if line.DiscountValue != 0 {
+			return nil, fmt.Errorf("не указан тип скидки")
+		}
+	case DiscountPercent:
+		if line.DiscountValue < 0 || line.DiscountValue > 100 {
+			return nil, fmt.Errorf("скидка в процентах должна быть от 0 до 100")
+		}
+		p.discount = gross * line.DiscountValue / 100
+	case DiscountFixed:
+		if line.DiscountValue < 0 || line.DiscountValue > gross {
+			return nil, fmt.Errorf("скидка не может быть больше суммы позиции")
+		}
+		p.discount = line.DiscountValue
+	default:
+		return nil, fmt.Errorf("неизвестный тип скидки: %s", line.DiscountType)
+	}
+	if !allowOverride && p.discount*100 > gross*cashierMaxDiscountPercent {
+		return nil, fmt.Errorf("%w: скидка больше %d%%", ErrPriceOverrideForbidden, cashierMaxDiscountPercent)
+	}
+
+	if p.tier == model.PriceTierRetail && !p.overridden {
+		promo, err := activePromotion(tx, item, now)
+		if err != nil {
+			return nil, err
+		}
+		if promo != nil {
+			if promoDiscount := gross * promo.Percent / 100; promoDiscount > p.discount {
+				p.discount = promoDiscount
+				p.promotionID = &promo.ID
+			}
+		}
+	}
+
+	p.total = gross - p.discount
+	return p, nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PromotionRepository struct {
+	DB *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) *PromotionRepository {
+	return &PromotionRepository{DB: db}
+}
+
+func validatePromotion(p *model.Promotion) error {
+	if p.Name == "" {
+		return fmt.Errorf("название акции обязательно")
+	}
+	if (p.ItemID == nil) == (p.Brand == "") {
+		return fmt.Errorf("укажите либо товар, либо бренд")
+	}
+	if p.Percent <= 0 || p.Percent > 100 {
+		return fmt.Errorf("скидка по акции должна быть от 1 до 100 процентов")
+	}
+	if p.StartsAt.IsZero() {
+		return fmt.Errorf("не указана дата начала акции")
+	}
+	if p.EndsAt != nil && !p.EndsAt.After(p.StartsAt) {
+		return fmt.Errorf("акция заканчивается раньше, чем начинается")
+	}
+	return nil
+}
+
+func (r *PromotionRepository) CreatePromotion(p *model.Promotion) error {
+	if err := validatePromotion(p); err != nil {
+		return err
+	}
+	return r.DB.Create(p).Error
+}
+
+func (r *PromotionRepository) UpdatePromotion(id uint, p *model.Promotion) (*model.Promotion, error) {
+	var existing model.Promotion
+	if err := r.DB.First(&existing, id).Error; err != nil {
+		return nil, err
+	}
+	if err := validatePromotion(p); err != nil {
+		return nil, err
+	}
+	p.ID = existing.ID
+	p.CreatedAt = existing.CreatedAt
+	if err := r.DB.Save(p).Error; err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListPromotions — все акции или только действующие сейчас
+func (r *PromotionRepository) ListPromotions(activeOnly bool) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	query := r.DB.Model(&model.Promotion{})
+	if activeOnly {
+		now := time.Now()
+		query = query.Where("active AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", now, now)
+	}
+	err := query.Order("starts_at desc").Find(&promotions).Error
+	return promotions, err
+}
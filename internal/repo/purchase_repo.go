@@ -0,0 +1,324 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PurchaseRepository struct {
+	DB *gorm.DB
+}
+
+func NewPurchaseRepository(db *gorm.DB) *PurchaseRepository {
+	return &PurchaseRepository{DB: db}
+}
+
+func validateSupplier(s *model.Supplier) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return fmt.Errorf("название поставщика обязательно")
+	}
+	return nil
+}
+
+func (r *PurchaseRepository) CreateSupplier(s *model.Supplier) error {
+	if err := validateSupplier(s); err != nil {
+		return err
+	}
+	return r.DB.Create(s).Error
+}
+
+func (r *PurchaseRepository) UpdateSupplier(id uint, s *model.Supplier) (*model.Supplier, error) {
+	var existing model.Supplier
+	if err := r.DB.First(&existing, id).Error; err != nil {
+		return nil, err
+	}
+	if err := validateSupplier(s); err != nil {
+		return nil, err
+	}
+	s.ID = existing.ID
+	s.CreatedAt = existing.CreatedAt
+	if err := r.DB.Save(s).Error; err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *PurchaseRepository) ListSuppliers() ([]model.Supplier, error) {
+	var suppliers []model.Supplier
+	err := r.DB.Order("name").Find(&suppliers).Error
+	return suppliers, err
+}
+
+// PurchaseOrderFilter — фильтр заказов по статусу и поставщику
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID uint
+}
+
+func (r *PurchaseRepository) ListPurchaseOrders(f PurchaseOrderFilter) ([]model.PurchaseOrder, error) {
+	query := r.DB.Preload("Supplier").Preload("Lines.Item")
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	if f.SupplierID != 0 {
+		query = query.Where("supplier_id = ?", f.SupplierID)
+	}
+
+	var orders []model.PurchaseOrder
+	err := query.Order("created_at desc").Find(&orders).Error
+	return orders, err
+}
+
+func (r *PurchaseRepository) GetPurchaseOrder(id uint) (*model.PurchaseOrder, error) {
+	var po model.PurchaseOrder
+	if err := r.DB.Preload("Supplier").Preload("Lines.Item").First(&po, id).Error; err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+// validatePurchaseLines проверяет позиции заказа и что поставщик и товары существуют
+func validatePurchaseLines(tx *gorm.DB, po *model.PurchaseOrder) error {
+	if err := tx.First(&model.Supplier{}, po.SupplierID).Error; err != nil {
+		return fmt.Errorf("поставщик %d не найден", po.SupplierID)
+	}
+	if len(po.Lines) == 0 {
+		return fmt.Errorf("заказ не содержит позиций")
+	}
+	for i := range po.Lines {
+		line := &po.Lines[i]
+		if line.Quantity <= 0 {
+			return fmt.Errorf("позиция %d: количество должно быть больше нуля", i+1)
+		}
+		if line.UnitCost < 0 {
+			return fmt.Errorf("позиция %d: цена закупки не может быть отрицательной", i+1)
+		}
+		if err := tx.First(&model.Item{}, line.ItemID).Error; err != nil {
+			return fmt.Errorf("позиция %d: товар %d не найден", i+1, line.ItemID)
+		}
+		line.ID = 0
+		line.ReceivedQuantity = 0
+		line.Item = model.Item{}
+	}
+	return nil
+}
+
+// CreatePurchaseOrder создаёт черновик заказа поставщику
+func (r *PurchaseRepository) CreatePurchaseOrder(po *model.PurchaseOrder) error {
+	po.ID = 0
+	po.Status = model.PurchaseDraft
+	po.SentAt = nil
+	po.ReceivedAt = nil
+	po.Supplier = model.Supplier{}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := validatePurchaseLines(tx, po); err != nil {
+			return err
+		}
+		return tx.Create(po).Error
+	})
+}
+
+// lockPurchaseOrder читает заказ с позициями под блокировкой
+func lockPurchaseOrder(tx *gorm.DB, id uint) (*model.PurchaseOrder, error) {
+	var po model.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&po, id).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("purchase_order_id = ?", id).Order("id").Find(&po.Lines).Error; err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+// UpdatePurchaseOrder меняет поставщика, заметку и позиции черновика
+func (r *PurchaseRepository) UpdatePurchaseOrder(id uint, update *model.PurchaseOrder) (*model.PurchaseOrder, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		po, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if po.Status != model.PurchaseDraft {
+			return fmt.Errorf("менять можно только черновик заказа")
+		}
+
+		update.ID = po.ID
+		if err := validatePurchaseLines(tx, update); err != nil {
+			return err
+		}
+		if err := tx.Where("purchase_order_id = ?", id).Delete(&model.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		for i := range update.Lines {
+			update.Lines[i].PurchaseOrderID = id
+		}
+		if err := tx.Create(&update.Lines).Error; err != nil {
+			return err
+		}
+		return tx.Model(po).Updates(map[string]interface{}{
+			"supplier_id": update.SupplierID,
+			"notes":       update.Notes,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetPurchaseOrder(id)
+}
+
+// SendPurchaseOrder отмечает черновик как отправленный поставщику
+func (r *PurchaseRepository) SendPurchaseOrder(id uint) (*model.PurchaseOrder, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		po, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if po.Status != model.PurchaseDraft {
+			return fmt.Errorf("отправить можно только черновик заказа")
+		}
+		return tx.Model(po).Updates(map[string]interface{}{
+			"status":  model.PurchaseSent,
+			"sent_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetPurchaseOrder(id)
+}
+
+// CancelPurchaseOrder отменяет заказ. Уже пришедший товар остаётся на складе,
+// отменяется только то, что ещё не пришло.
+func (r *PurchaseRepository) CancelPurchaseOrder(id uint) (*model.PurchaseOrder, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		po, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if po.Status == model.PurchaseReceived || po.Status == model.PurchaseCancelled {
+			return fmt.Errorf("заказ в статусе %s нельзя отменить", po.Status)
+		}
+		return tx.Model(po).Update("status", model.PurchaseCancelled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetPurchaseOrder(id)
+}
+
+// ReceiptLine — сколько пришло по позиции заказа. Пустая цена — цена из заказа.
+type ReceiptLine struct {
+	LineID   uint `json:"lineId"`
+	Quantity int  `json:"quantity"`
+	UnitCost *int `json:"unitCost"`
+}
+
+// ReceiptRequest — приёмка товара по заказу. Без позиций принимается весь остаток заказа.
+type ReceiptRequest struct {
+	Lines      []ReceiptLine `json:"lines"`
+	LocationID *uint         `json:"locationId"` // куда принимаем; пусто — основное место
+	UserID     *uint         `json:"-"`
+}
+
+// ReceivePurchaseOrder принимает товар по заказу: приход на склад по каждой позиции,
+// цена закупки запоминается как себестоимость товара
+func (r *PurchaseRepository) ReceivePurchaseOrder(id uint, req ReceiptRequest) (*model.PurchaseOrder, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		po, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if po.Status != model.PurchaseSent && po.Status != model.PurchasePartiallyReceived {
+			return fmt.Errorf("принять товар можно только по отправленному заказу")
+		}
+
+		itemIDs := make([]uint, 0, len(po.Lines))
+		for _, line := range po.Lines {
+			itemIDs = append(itemIDs, line.ItemID)
+		}
+		if err := lockItems(tx, itemIDs); err != nil {
+			return err
+		}
+
+		lines := make(map[uint]*model.PurchaseOrderLine, len(po.Lines))
+		for i := range po.Lines {
+			lines[po.Lines[i].ID] = &po.Lines[i]
+		}
+
+		receipt := req.Lines
+		if len(receipt) == 0 {
+			for _, line := range po.Lines {
+				if remaining := line.Quantity - line.ReceivedQuantity; remaining > 0 {
+					receipt = append(receipt, ReceiptLine{LineID: line.ID, Quantity: remaining})
+				}
+			}
+		}
+
+		now := time.Now()
+		for _, rl := range receipt {
+			line, ok := lines[rl.LineID]
+			if !ok {
+				return fmt.Errorf("позиция %d не относится к заказу %d", rl.LineID, id)
+			}
+			remaining := line.Quantity - line.ReceivedQuantity
+			if rl.Quantity <= 0 || rl.Quantity > remaining {
+				return fmt.Errorf("позиция %d: можно принять от 1 до %d шт.", rl.LineID, remaining)
+			}
+			cost := line.UnitCost
+			if rl.UnitCost != nil {
+				if *rl.UnitCost < 0 {
+					return fmt.Errorf("позиция %d: цена закупки не может быть отрицательной", rl.LineID)
+				}
+				cost = *rl.UnitCost
+			}
+
+			_, err := applyStockMovement(tx, &model.StockMovement{
+				ItemID:          line.ItemID,
+				Type:            model.MovementReceipt,
+				Quantity:        rl.Quantity,
+				LocationID:      req.LocationID,
+				UserID:          req.UserID,
+				Reason:          fmt.Sprintf("приёмка по заказу %d", id),
+				PurchaseOrderID: &po.ID,
+				CreatedAt:       now,
+			})
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Item{}).Where("id = ?", line.ItemID).Update("last_cost", cost).Error; err != nil {
+				return err
+			}
+
+			line.ReceivedQuantity += rl.Quantity
+			err = tx.Model(line).Updates(map[string]interface{}{
+				"received_quantity": line.ReceivedQuantity,
+				"unit_cost":         cost,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		status := model.PurchaseReceived
+		for _, line := range po.Lines {
+			if line.ReceivedQuantity < line.Quantity {
+				status = model.PurchasePartiallyReceived
+				break
+			}
+		}
+		return tx.Model(po).Updates(map[string]interface{}{
+			"status":      status,
+			"received_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetPurchaseOrder(id)
+}
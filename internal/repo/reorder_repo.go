@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Группировки списка на закупку
+const (
+	ReorderByBrand    = "brand"
+	ReorderBySupplier = "supplier"
+)
+
+// onOrderSQL — сколько товара уже заказано у поставщиков и ещё не пришло
+const onOrderSQL = `(SELECT COALESCE(SUM(l.quantity - l.received_quantity), 0)
+	FROM purchase_order_lines l
+	JOIN purchase_orders po ON po.id = l.purchase_order_id
+	WHERE l.item_id = items.id AND po.status IN ('sent', 'partially_received'))`
+
+// inTransitSQL — сколько товара отгружено по перемещениям и ещё не принято.
+// Такой товар не числится ни в одном месте, но и не пропал — это не нехватка.
+const inTransitSQL = `(SELECT COALESCE(SUM(l.quantity), 0)
+	FROM transfer_lines l
+	JOIN transfers t ON t.id = l.transfer_id
+	WHERE l.item_id = items.id AND t.status = 'shipped')`
+
+// lastPurchaseSQL — поставщик и цена из последнего заказа с этим товаром
+const lastPurchaseSQL = `(SELECT DISTINCT ON (l.item_id) l.item_id, po.supplier_id, l.unit_cost
+	FROM purchase_order_lines l
+	JOIN purchase_orders po ON po.id = l.purchase_order_id
+	WHERE po.status <> 'cancelled'
+	ORDER BY l.item_id, po.created_at DESC, l.id DESC)`
+
+// LowStockItem — товар ниже точки заказа
+type LowStockItem struct {
+	ItemID       uint   `json:"itemId"`
+	Name         string `json:"name"`
+	PartNumber   string `json:"partNumber"`
+	Brand        string `json:"brand"`
+	Stock        int    `json:"stock"`
+	MinStock     int    `json:"minStock"`
+	ReorderQty   int    `json:"reorderQty"`
+	InTransit    int    `json:"inTransit"`    // в пути между местами хранения
+	OnOrder      int    `json:"onOrder"`      // уже заказано и не пришло
+	SupplierID   *uint  `json:"supplierId"`   // из последнего заказа
+	SupplierName string `json:"supplierName"` // из последнего заказа
+	UnitCost     int    `json:"unitCost"`     // цена последней закупки
+	Suggested    int    `json:"suggested"`    // сколько заказать
+}
+
+// ReorderGroup — товары на закупку одного бренда или поставщика
+type ReorderGroup struct {
+	Key       string         `json:"key"`
+	Label     string         `json:"label"`
+	Items     []LowStockItem `json:"items"`
+	Quantity  int            `json:"quantity"`
+	TotalCost int            `json:"totalCost"`
+}
+
+// LowStockItems — товары, у которых остаток вместе с товаром в пути меньше точки заказа
+func (r *ItemRepository) LowStockItems() ([]LowStockItem, error) {
+	var items []LowStockItem
+	err := r.DB.Table("items").
+		Select(`items.id AS item_id, items.name, items.part_number, items.brand,
+			items.stock, items.min_stock, items.reorder_qty,
+			` + inTransitSQL + ` AS in_transit,
+			` + onOrderSQL + ` AS on_order,
+			last.supplier_id, COALESCE(suppliers.name, '') AS supplier_name,
+			COALESCE(last.unit_cost, items.last_cost) AS unit_cost`).
+		Joins("LEFT JOIN " + lastPurchaseSQL + " last ON last.item_id = items.id").
+		Joins("LEFT JOIN suppliers ON suppliers.id = last.supplier_id").
+		Where("items.min_stock > 0 AND items.stock + " + inTransitSQL + " < items.min_stock").
+		Order("items.brand, items.name").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// заказываем партией не меньше ReorderQty, но так, чтобы с учётом
+	// товара в пути и уже заказанного остаток дошёл до точки заказа
+	for i := range items {
+		item := &items[i]
+		if need := item.MinStock - item.Stock - item.InTransit - item.OnOrder; need > 0 {
+			item.Suggested = max(need, item.ReorderQty)
+		}
+	}
+	return items, nil
+}
+
+// inTransitQuantity — сколько товара сейчас в пути по перемещениям
+func inTransitQuantity(tx *gorm.DB, itemID uint) (int, error) {
+	var quantity int
+	err := tx.Table("items").
+		Select(inTransitSQL).
+		Where("items.id = ?", itemID).
+		Scan(&quantity).Error
+	return quantity, err
+}
+
+// SuggestedPurchases — список на закупку, сгруппированный по бренду или поставщику.
+// Товары, которые уже заказаны в достаточном количестве, не попадают в список.
+func (r *ItemRepository) SuggestedPurchases(groupBy string) ([]ReorderGroup, error) {
+	if groupBy != ReorderByBrand && groupBy != ReorderBySupplier {
+		return nil, fmt.Errorf("неизвестная группировка: %s", groupBy)
+	}
+
+	items, err := r.LowStockItems()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []ReorderGroup{}
+	index := map[string]int{}
+	for _, item := range items {
+		if item.Suggested == 0 {
+			continue
+		}
+
+		key, label := item.Brand, item.Brand
+		if groupBy == ReorderBySupplier {
+			key, label = "", "Без поставщика"
+			if item.SupplierID != nil {
+				key, label = fmt.Sprint(*item.SupplierID), item.SupplierName
+			}
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ReorderGroup{Key: key, Label: label})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+		groups[i].Quantity += item.Suggested
+		groups[i].TotalCost += item.Suggested * item.UnitCost
+	}
+	return groups, nil
+}
+
+// ListStockAlerts — уведомления о низком остатке, новые первыми; all=false — только непросмотренные
+func (r *ItemRepository) ListStockAlerts(all bool) ([]model.StockAlert, error) {
+	query := r.DB.Preload("Item")
+	if !all {
+		query = query.Where("NOT acknowledged")
+	}
+	var alerts []model.StockAlert
+	err := query.Order("created_at desc").Find(&alerts).Error
+	return alerts, err
+}
+
+// AcknowledgeStockAlert отмечает уведомление просмотренным
+func (r *ItemRepository) AcknowledgeStockAlert(id uint, userID *uint) (*model.StockAlert, error) {
+	var alert model.StockAlert
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&alert, id).Error; err != nil {
+			return err
+		}
+		if alert.Acknowledged {
+			return nil
+		}
+		now := time.Now()
+		alert.Acknowledged = true
+		alert.AcknowledgedBy = userID
+		alert.AcknowledgedAt = &now
+		return tx.Model(&alert).Updates(map[string]interface{}{
+			"acknowledged":    true,
+			"acknowledged_by": userID,
+			"acknowledged_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
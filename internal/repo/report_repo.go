@@ -0,0 +1,173 @@
+package repo
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"warehouse-backend/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository struct {
+	DB *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{DB: db}
+}
+
+// Группировки отчёта по продажам
+const (
+	GroupByDay      = "day"
+	GroupByWeek     = "week"
+	GroupByMonth    = "month"
+	GroupByBrand    = "brand"
+	GroupByItem     = "item"
+	GroupByCustomer = "customer"
+	GroupByCashier  = "cashier"
+)
+
+type reportGroup struct {
+	key, label string
+	join       string
+	byTime     bool
+}
+
+// reportGroups — SQL-выражения ключа и подписи для каждой группировки.
+// Дни, недели и месяцы считаются по времени магазина.
+func reportGroups() map[string]reportGroup {
+	localTime := fmt.Sprintf("(sales.sold_at AT TIME ZONE '%s')", utils.BusinessTimezoneName())
+	return map[string]reportGroup{
+		GroupByDay:   {key: "to_char(date_trunc('day', " + localTime + "), 'YYYY-MM-DD')", byTime: true},
+		GroupByWeek:  {key: "to_char(date_trunc('week', " + localTime + "), 'YYYY-MM-DD')", byTime: true},
+		GroupByMonth: {key: "to_char(date_trunc('month', " + localTime + "), 'YYYY-MM')", byTime: true},
+		GroupByBrand: {key: "items.brand"},
+		GroupByItem:  {key: "items.id::text", label: "MAX(items.name || ' ' || items.part_number)"},
+		GroupByCustomer: {
+			// покупатели из справочника — по ID, остальные — по имени
+			key:   "COALESCE(sales.customer_id::text, sales.customer)",
+			label: "COALESCE(MAX(customers.name), MAX(sales.customer))",
+			join:  "LEFT JOIN customers ON sales.customer_id = customers.id",
+		},
+		GroupByCashier: {
+			key:   "COALESCE(sales.user_id::text, '')",
+			label: "COALESCE(MAX(users.username), '')",
+			join:  "LEFT JOIN users ON sales.user_id = users.id",
+		},
+	}
+}
+
+// Показатели для сортировки топа
+var reportSortColumns = map[string]string{
+	"revenue":  "revenue",
+	"quantity": "quantity",
+	"margin":   "margin",
+}
+
+// SalesReportQuery — период [From, To), группировка и необязательный топ-N
+type SalesReportQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+	Brand   string
+	Sort    string // revenue, quantity или margin — для группировок не по времени
+	Limit   int    // 0 — без ограничения
+}
+
+// SalesReportRow — строка отчёта. Все суммы за вычетом возвратов.
+type SalesReportRow struct {
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Revenue  int    `json:"revenue"`  // выручка
+	Quantity int    `json:"quantity"` // продано штук
+	Cost     int    `json:"cost"`     // себестоимость
+	Margin   int    `json:"margin"`   // валовая прибыль
+	Lines    int    `json:"lines"`    // позиций в чеках
+
+	MarginPercent float64 `json:"marginPercent" gorm:"-"` // прибыль в процентах от выручки
+}
+
+func (row *SalesReportRow) computeMarginPercent() {
+	if row.Revenue != 0 {
+		row.MarginPercent = math.Round(float64(row.Margin)*10000/float64(row.Revenue)) / 100
+	}
+}
+
+// Выражения показателей: возвраты вычитаются
+const (
+	reportRevenueSQL  = "COALESCE(SUM(sales.total_price - sales.refunded_amount), 0)"
+	reportQuantitySQL = "COALESCE(SUM(sales.quantity - sales.returned_quantity), 0)"
+	reportCostSQL     = "COALESCE(SUM((sales.quantity - sales.returned_quantity) * sales.unit_cost), 0)"
+)
+
+func ValidGroupBy(groupBy string) bool {
+	_, ok := reportGroups()[groupBy]
+	return ok
+}
+
+// SalesReport считает выручку, количество и маржу по группам за период
+func (r *ReportRepository) SalesReport(q SalesReportQuery) ([]SalesReportRow, error) {
+	group, ok := reportGroups()[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("неизвестная группировка: %s", q.GroupBy)
+	}
+	label := group.label
+	if label == "" {
+		label = group.key
+	}
+
+	query := r.DB.Table("sales").
+		Select(fmt.Sprintf("%s AS key, %s AS label, %s AS revenue, %s AS quantity, %s AS cost, %s - %s AS margin, COUNT(*) AS lines",
+			group.key, label, reportRevenueSQL, reportQuantitySQL, reportCostSQL, reportRevenueSQL, reportCostSQL)).
+		Joins("JOIN items ON sales.item_id = items.id").
+		Where("sales.sold_at >= ? AND sales.sold_at < ?", q.From, q.To).
+		Group(group.key)
+	if group.join != "" {
+		query = query.Joins(group.join)
+	}
+	if q.Brand != "" {
+		query = query.Where("items.brand = ?", q.Brand)
+	}
+
+	if group.byTime {
+		query = query.Order("key")
+	} else {
+		column, ok := reportSortColumns[q.Sort]
+		if !ok {
+			column = "revenue"
+		}
+		query = query.Order(column + " DESC").Order("key")
+	}
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	var rows []SalesReportRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].computeMarginPercent()
+	}
+	return rows, nil
+}
+
+// SalesTotals — итоги за период по тем же правилам, что и SalesReport
+func (r *ReportRepository) SalesTotals(q SalesReportQuery) (*SalesReportRow, error) {
+	query := r.DB.Table("sales").
+		Select(fmt.Sprintf("'total' AS key, %s AS revenue, %s AS quantity, %s AS cost, %s - %s AS margin, COUNT(*) AS lines",
+			reportRevenueSQL, reportQuantitySQL, reportCostSQL, reportRevenueSQL, reportCostSQL)).
+		Joins("JOIN items ON sales.item_id = items.id").
+		Where("sales.sold_at >= ? AND sales.sold_at < ?", q.From, q.To)
+	if q.Brand != "" {
+		query = query.Where("items.brand = ?", q.Brand)
+	}
+
+	var totals SalesReportRow
+	if err := query.Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	totals.computeMarginPercent()
+	return &totals, nil
+}
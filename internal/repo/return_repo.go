@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReturnRequest — данные возврата. Пустое количество означает возврат всего
+// остатка позиции, пустая сумма — возврат пропорционально цене продажи.
+type ReturnRequest struct {
+	Quantity     int    `json:"quantity"`
+	RefundAmount *int   `json:"refundAmount"`
+	Reason       string `json:"reason"`
+	LocationID   *uint  `json:"locationId"` // куда вернуть; пусто — туда, откуда продали
+	UserID       *uint  `json:"-"`
+}
+
+// ReturnSale оформляет полный или частичный возврат по позиции чека
+// и возвращает товар на склад
+func (r *ItemRepository) ReturnSale(saleID uint, req ReturnRequest) (*model.SaleReturn, error) {
+	var ret *model.SaleReturn
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var sale model.Sale
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sale, saleID).Error; err != nil {
+			return err
+		}
+
+		remaining := sale.Quantity - sale.ReturnedQuantity
+		quantity := req.Quantity
+		if quantity == 0 {
+			quantity = remaining
+		}
+		if quantity <= 0 || quantity > remaining {
+			return fmt.Errorf("можно вернуть не больше %d шт.", remaining)
+		}
+
+		maxRefund := sale.TotalPrice - sale.RefundedAmount
+		refund := sale.TotalPrice * quantity / sale.Quantity
+		if quantity == remaining {
+			// последний возврат забирает остаток суммы, чтобы не терять копейки на округлении
+			refund = maxRefund
+		}
+		if req.RefundAmount != nil {
+			refund = *req.RefundAmount
+		}
+		if refund < 0 || refund > maxRefund {
+			return fmt.Errorf("сумма возврата должна быть от 0 до %d", maxRefund)
+		}
+
+		err := tx.Model(&sale).Updates(map[string]interface{}{
+			"returned_quantity": sale.ReturnedQuantity + quantity,
+			"refunded_amount":   sale.RefundedAmount + refund,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		debtReduced, err := applyOrderRefund(tx, sale.OrderID, refund)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		ret = &model.SaleReturn{
+			SaleID:       sale.ID,
+			ItemID:       sale.ItemID,
+			Quantity:     quantity,
+			RefundAmount: refund,
+			DebtReduced:  debtReduced,
+			Reason:       req.Reason,
+			UserID:       req.UserID,
+			ReturnedAt:   now,
+		}
+		if err := tx.Create(ret).Error; err != nil {
+			return err
+		}
+
+		locationID := req.LocationID
+		if locationID == nil {
+			locationID = sale.LocationID
+		}
+		_, err = applyStockMovement(tx, &model.StockMovement{
+			ItemID:     sale.ItemID,
+			Type:       model.MovementReturn,
+			Quantity:   quantity,
+			LocationID: locationID,
+			UserID:     req.UserID,
+			Reason:     req.Reason,
+			SaleID:     &sale.ID,
+			CreatedAt:  now,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// applyOrderRefund уменьшает сумму чека на возврат. Если по чеку есть долг,
+// возврат сначала списывает его, а деньгами выдаётся только остаток.
+// Возвращает, сколько списано с долга.
+func applyOrderRefund(tx *gorm.DB, orderID *uint, refund int) (int, error) {
+	if orderID == nil || refund == 0 {
+		return 0, nil
+	}
+	var order model.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, *orderID).Error; err != nil {
+		return 0, err
+	}
+
+	debtReduced := min(refund, max(order.Due(), 0))
+	order.RefundedAmount += refund
+	order.PaidAmount -= refund - debtReduced
+	order.UpdatePaymentStatus()
+
+	err := tx.Model(&order).Updates(map[string]interface{}{
+		"refunded_amount": order.RefundedAmount,
+		"paid_amount":     order.PaidAmount,
+		"payment_status":  order.PaymentStatus,
+	}).Error
+	return debtReduced, err
+}
+
+func (r *ItemRepository) GetSaleReturns(saleID uint) ([]model.SaleReturn, error) {
+	var returns []model.SaleReturn
+	err := r.DB.Where("sale_id = ?", saleID).Order("returned_at desc").Find(&returns).Error
+	return returns, err
+}
@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"strings"
+	"unicode"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// порог нечёткого совпадения для опечаток (pg_trgm word_similarity)
+const searchSimilarityThreshold = "0.4"
+
+var lookalikeReplacer = func() *strings.Replacer {
+	cyr := []rune(model.SearchCyrillicLookalikes)
+	lat := []rune(model.SearchLatinLookalikes)
+	pairs := make([]string, 0, len(cyr)*2)
+	for i := range cyr {
+		pairs = append(pairs, string(cyr[i]), string(lat[i]))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// foldSearch приводит строку к тому же виду, что и model.ItemSearchTextSQL
+func foldSearch(s string) string {
+	return lookalikeReplacer.Replace(strings.ToLower(s))
+}
+
+// normalizePartNumber приводит номер к виду model.ItemPartNumberNormSQL:
+// "04465-33 450" и "0446533450" дают одно и то же
+func normalizePartNumber(s string) string {
+	var b strings.Builder
+	for _, r := range foldSearch(s) {
+		if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'а' && r <= 'я') || r == 'ё' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// prefixTSQuery собирает запрос "слово1:* & слово2:*" для to_tsquery
+func prefixTSQuery(folded string) string {
+	words := strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// SearchItems ищет товары по названию, номеру, бренду и модели: частичный номер
+// без учёта дефисов и пробелов, полнотекстовый поиск по словам и нечёткое
+// совпадение для опечаток. Результат отсортирован по релевантности.
+func (r *ItemRepository) SearchItems(query string, limit int) ([]model.Item, error) {
+	folded := foldSearch(strings.TrimSpace(query))
+	norm := normalizePartNumber(query)
+	tsQuery := prefixTSQuery(folded)
+
+	conditions := []string{"? <% " + model.ItemSearchTextSQL}
+	conditionArgs := []interface{}{folded}
+	scores := []string{"word_similarity(?, " + model.ItemSearchTextSQL + ")"}
+	scoreArgs := []interface{}{folded}
+
+	if norm != "" {
+		conditions = append(conditions, model.ItemPartNumberNormSQL+" LIKE '%' || ? || '%'")
+		conditionArgs = append(conditionArgs, norm)
+		// точное совпадение номера выше всего, затем совпадение по началу
+		scores = append(scores, "CASE WHEN "+model.ItemPartNumberNormSQL+" = ? THEN 2 "+
+			"WHEN "+model.ItemPartNumberNormSQL+" LIKE ? || '%' THEN 1.5 "+
+			"ELSE similarity("+model.ItemPartNumberNormSQL+", ?) END")
+		scoreArgs = append(scoreArgs, norm, norm, norm)
+	}
+	if tsQuery != "" {
+		conditions = append(conditions, "to_tsvector('simple', "+model.ItemSearchTextSQL+") @@ to_tsquery('simple', ?)")
+		conditionArgs = append(conditionArgs, tsQuery)
+		scores = append(scores, "ts_rank(to_tsvector('simple', "+model.ItemSearchTextSQL+"), to_tsquery('simple', ?))")
+		scoreArgs = append(scoreArgs, tsQuery)
+	}
+
+	var items []model.Item
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// SET не принимает параметры, поэтому set_config; true — только на эту транзакцию
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", searchSimilarityThreshold).Error; err != nil {
+			return err
+		}
+		return withLocations(tx.Preload("Images")).
+			Where(strings.Join(conditions, " OR "), conditionArgs...).
+			Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "GREATEST(" + strings.Join(scores, ", ") + ") DESC, id",
+				Vars:               scoreArgs,
+				WithoutParentheses: true,
+			}}).
+			Limit(limit).
+			Find(&items).Error
+	})
+	return items, err
+}
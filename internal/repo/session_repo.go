@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"errors"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+
+func (r *UserRepo) CreateRefreshToken(token *model.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+// RotateRefreshToken гасит старый refresh-токен и сохраняет новый.
+// Повторное использование уже погашенного токена означает утечку —
+// в этом случае отзываются все сессии пользователя.
+func (r *UserRepo) RotateRefreshToken(oldHash string, next *model.RefreshToken) (*model.User, error) {
+	var user model.User
+	reused := false
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var current model.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", oldHash).
+			First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			reused = true
+			return revokeUserTokens(tx, current.UserID)
+		}
+		if time.Now().After(current.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		if err := tx.First(&user, current.UserID).Error; err != nil {
+			return err
+		}
+		if user.Disabled {
+			return ErrInvalidRefreshToken
+		}
+
+		next.UserID = user.ID
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&current).Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": next.ID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrInvalidRefreshToken
+	}
+	return &user, nil
+}
+
+// RevokeRefreshToken завершает одну сессию (logout)
+func (r *UserRepo) RevokeRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	err := r.DB.Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", token.ID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeUserTokens завершает все сессии пользователя
+func (r *UserRepo) RevokeUserTokens(userID uint) error {
+	return revokeUserTokens(r.DB, userID)
+}
+
+func revokeUserTokens(tx *gorm.DB, userID uint) error {
+	return tx.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// UpdatePassword меняет пароль и отзывает все сессии пользователя
+func (r *UserRepo) UpdatePassword(userID uint, hash string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Update("password", hash).Error; err != nil {
+			return err
+		}
+		return revokeUserTokens(tx, userID)
+	})
+}
+
+// SetDisabled отключает или включает пользователя; при отключении все сессии отзываются
+func (r *UserRepo) SetDisabled(userID uint, disabled bool) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Update("disabled", disabled).Error; err != nil {
+			return err
+		}
+		if !disabled {
+			return nil
+		}
+		return revokeUserTokens(tx, userID)
+	})
+}
@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"fmt"
+	"slices"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InsufficientStockError — в месте хранения меньше товара, чем нужно списать
+type InsufficientStockError struct {
+	ItemID     uint
+	Name       string
+	LocationID uint
+	Requested  int
+	Available  int
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("недостаточно товара на складе: %s (нужно %d, в наличии %d)", e.Name, e.Requested, e.Available)
+}
+
+// StockMismatch — товар, у которого остаток не сходится с журналом движения
+// или с суммой по местам хранения
+type StockMismatch struct {
+	ItemID        uint   `json:"itemId"`
+	Name          string `json:"name"`
+	PartNumber    string `json:"partNumber"`
+	Stock         int    `json:"stock"`
+	LedgerStock   int    `json:"ledgerStock"`
+	LocationStock int    `json:"locationStock"`
+}
+
+// defaultLocationID — основное место основного склада, туда идёт всё,
+// для чего место не указано
+func defaultLocationID(tx *gorm.DB) (uint, error) {
+	var ids []uint
+	err := tx.Model(&model.Location{}).
+		Joins("JOIN warehouses ON warehouses.id = locations.warehouse_id").
+		Where("warehouses.is_default AND locations.is_default").
+		Order("locations.id").
+		Limit(1).
+		Pluck("locations.id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("не задано основное место хранения")
+	}
+	return ids[0], nil
+}
+
+// resolveLocation — указанное место хранения или основное, если не указано
+func resolveLocation(tx *gorm.DB, locationID *uint) (uint, error) {
+	if locationID == nil {
+		return defaultLocationID(tx)
+	}
+	if err := tx.First(&model.Location{}, *locationID).Error; err != nil {
+		return 0, fmt.Errorf("место хранения %d не найдено", *locationID)
+	}
+	return *locationID, nil
+}
+
+// lockItems блокирует строки товаров (FOR UPDATE) по возрастанию ID.
+// Транзакции, которые меняют несколько товаров, берут блокировки в одном
+// порядке и не ждут друг друга по кругу, в каком бы порядке ни шли позиции.
+func lockItems(tx *gorm.DB, ids []uint) error {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var items []model.Item
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id IN ?", sorted).
+		Order("id").
+		Find(&items).Error
+}
+
+// changeStock меняет остаток товара в месте хранения внутри транзакции.
+// Строка товара блокируется (FOR UPDATE), а списание выполняется с условием
+// quantity >= нужного, так что остаток не уйдёт в минус даже при одновременных
+// продажах. Items.stock меняется на ту же величину.
+func changeStock(tx *gorm.DB, itemID, locationID uint, delta int) (*model.Item, error) {
+	var item model.Item
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, itemID).Error; err != nil {
+		return nil, err
+	}
+
+	if delta < 0 {
+		res := tx.Model(&model.ItemStock{}).
+			Where("item_id = ? AND location_id = ? AND quantity >= ?", itemID, locationID, -delta).
+			UpdateColumn("quantity", gorm.Expr("quantity + ?", delta))
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			var available []int
+			if err := tx.Model(&model.ItemStock{}).
+				Where("item_id = ? AND location_id = ?", itemID, locationID).
+				Pluck("quantity", &available).Error; err != nil {
+				return nil, err
+			}
+			stockErr := &InsufficientStockError{
+				ItemID:     item.ID,
+				Name:       item.Name,
+				LocationID: locationID,
+				Requested:  -delta,
+			}
+			if len(available) > 0 {
+				stockErr.Available = available[0]
+			}
+			return nil, stockErr
+		}
+	} else if delta > 0 {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "item_id"}, {Name: "location_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"quantity": gorm.Expr("item_stocks.quantity + ?", delta)}),
+		}).Create(&model.ItemStock{ItemID: itemID, LocationID: locationID, Quantity: delta}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := tx.Model(&model.Item{}).Where("id = ?", itemID).
+		UpdateColumn("stock", gorm.Expr("stock + ?", delta)).Error
+	if err != nil {
+		return nil, err
+	}
+
+	item.Stock += delta
+	return &item, nil
+}
+
+// applyStockMovement меняет остаток и записывает движение в журнал.
+// Без места хранения движение проводится по основному месту.
+func applyStockMovement(tx *gorm.DB, mv *model.StockMovement) (*model.Item, error) {
+	locationID, err := resolveLocation(tx, mv.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	mv.LocationID = &locationID
+
+	item, err := changeStock(tx, mv.ItemID, locationID, mv.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(mv).Error; err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// setStock доводит остаток товара в месте хранения до заданного значения
+// корректировкой. Без места — основное место, но только если товар больше
+// нигде не лежит: иначе непонятно, в каком месте менять количество.
+func setStock(tx *gorm.DB, itemID uint, locationID *uint, stock int, userID *uint, reason string) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Item{}, itemID).Error; err != nil {
+		return err
+	}
+	if stock < 0 {
+		return fmt.Errorf("остаток не может быть отрицательным")
+	}
+
+	location, err := resolveLocation(tx, locationID)
+	if err != nil {
+		return err
+	}
+	if locationID == nil {
+		var elsewhere int64
+		err := tx.Model(&model.ItemStock{}).
+			Where("item_id = ? AND location_id <> ? AND quantity <> 0", itemID, location).
+			Count(&elsewhere).Error
+		if err != nil {
+			return err
+		}
+		if elsewhere > 0 {
+			return fmt.Errorf("товар лежит в нескольких местах хранения — укажите место (locationId)")
+		}
+	}
+
+	var current []int
+	err = tx.Model(&model.ItemStock{}).
+		Where("item_id = ? AND location_id = ?", itemID, location).
+		Pluck("quantity", &current).Error
+	if err != nil {
+		return err
+	}
+	delta := stock
+	if len(current) > 0 {
+		delta -= current[0]
+	}
+	if delta == 0 {
+		return nil
+	}
+
+	_, err = applyStockMovement(tx, &model.StockMovement{
+		ItemID:     itemID,
+		Type:       model.MovementAdjustment,
+		Quantity:   delta,
+		LocationID: &location,
+		UserID:     userID,
+		Reason:     reason,
+	})
+	return err
+}
+
+// AddStockMovement проводит ручное движение: приход, корректировку или списание
+func (r *ItemRepository) AddStockMovement(mv *model.StockMovement) (*model.Item, error) {
+	switch mv.Type {
+	case model.MovementReceipt:
+		if mv.Quantity <= 0 {
+			return nil, fmt.Errorf("количество прихода должно быть больше нуля")
+		}
+	case model.MovementWriteOff:
+		// списание всегда уменьшает остаток
+		if mv.Quantity > 0 {
+			mv.Quantity = -mv.Quantity
+		}
+		if mv.Quantity == 0 {
+			return nil, fmt.Errorf("количество списания должно быть больше нуля")
+		}
+	case model.MovementAdjustment:
+		if mv.Quantity == 0 {
+			return nil, fmt.Errorf("корректировка не меняет остаток")
+		}
+	default:
+		return nil, fmt.Errorf("недопустимый тип движения: %s", mv.Type)
+	}
+
+	var item *model.Item
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		item, err = applyStockMovement(tx, mv)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *ItemRepository) GetStockMovements(itemID uint) ([]model.StockMovement, error) {
+	var movements []model.StockMovement
+	err := r.DB.Where("item_id = ?", itemID).
+		Order("created_at desc, id desc").
+		Find(&movements).Error
+	return movements, err
+}
+
+// ReconcileStock находит товары, у которых остаток расходится с журналом
+// или с суммой по местам хранения
+func (r *ItemRepository) ReconcileStock() ([]StockMismatch, error) {
+	var mismatches []StockMismatch
+	err := r.DB.Table("items").
+		Select("items.id as item_id, items.name, items.part_number, items.stock, COALESCE(ledger.quantity, 0) as ledger_stock, COALESCE(placed.quantity, 0) as location_stock").
+		Joins("LEFT JOIN (SELECT item_id, SUM(quantity) AS quantity FROM stock_movements GROUP BY item_id) ledger ON ledger.item_id = items.id").
+		Joins("LEFT JOIN (SELECT item_id, SUM(quantity) AS quantity FROM item_stocks GROUP BY item_id) placed ON placed.item_id = items.id").
+		Where("items.stock <> COALESCE(ledger.quantity, 0) OR items.stock <> COALESCE(placed.quantity, 0)").
+		Scan(&mismatches).Error
+	return mismatches, err
+}
+
+// GetItemStock — где лежит товар: остатки по местам хранения
+func (r *ItemRepository) GetItemStock(itemID uint) ([]model.ItemStock, error) {
+	if err := r.DB.First(&model.Item{}, itemID).Error; err != nil {
+		return nil, err
+	}
+	var stocks []model.ItemStock
+	err := r.DB.Preload("Location.Warehouse").
+		Where("item_id = ? AND quantity <> 0", itemID).
+		Order("location_id").
+		Find(&stocks).Error
+	return stocks, err
+}
@@ -0,0 +1,357 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StocktakeCount — подсчитанное количество: по строке инвентаризации
+// или по товару и месту, если товара не было в снимке
+type StocktakeCount struct {
+	LineID     uint  `json:"lineId"`
+	ItemID     uint  `json:"itemId"`
+	LocationID uint  `json:"locationId"`
+	Counted    int   `json:"counted"`
+	UserID     *uint `json:"-"`
+}
+
+// StocktakeVariance — отчёт о расхождениях. Стоимость — по оптовой цене.
+type StocktakeVariance struct {
+	Stocktake     model.Stocktake       `json:"stocktake"`
+	Lines         []model.StocktakeLine `json:"lines"`     // только строки с расхождением
+	Counted       int                   `json:"counted"`   // посчитано строк
+	Uncounted     int                   `json:"uncounted"` // не посчитано — при утверждении не меняются
+	Surplus       int                   `json:"surplus"`   // излишки, шт.
+	Shortage      int                   `json:"shortage"`  // недостача, шт.
+	SurplusValue  int                   `json:"surplusValue"`
+	ShortageValue int                   `json:"shortageValue"`
+}
+
+// CreateStocktake начинает инвентаризацию: запоминает текущие остатки
+// по месту хранения и/или бренду как ожидаемые
+func (r *ItemRepository) CreateStocktake(st *model.Stocktake) error {
+	st.ID = 0
+	st.Status = model.StocktakeOpen
+	st.Reason = ""
+	st.ApprovedBy = nil
+	st.ApprovedAt = nil
+	st.Brand = strings.TrimSpace(st.Brand)
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.ItemStock{})
+		if st.LocationID != nil {
+			if err := tx.First(&model.Location{}, *st.LocationID).Error; err != nil {
+				return fmt.Errorf("место хранения %d не найдено", *st.LocationID)
+			}
+			query = query.Where("item_stocks.location_id = ?", *st.LocationID)
+		}
+		if st.Brand != "" {
+			query = query.Joins("JOIN items ON items.id = item_stocks.item_id").Where("items.brand = ?", st.Brand)
+		}
+
+		var stocks []model.ItemStock
+		if err := query.Order("item_stocks.location_id, item_stocks.item_id").Find(&stocks).Error; err != nil {
+			return err
+		}
+		if len(stocks) == 0 {
+			return fmt.Errorf("нет товаров для инвентаризации")
+		}
+
+		st.Lines = make([]model.StocktakeLine, 0, len(stocks))
+		for _, s := range stocks {
+			st.Lines = append(st.Lines, model.StocktakeLine{
+				ItemID:     s.ItemID,
+				LocationID: s.LocationID,
+				Expected:   s.Quantity,
+			})
+		}
+		return tx.Create(st).Error
+	})
+}
+
+func (r *ItemRepository) ListStocktakes(status string) ([]model.Stocktake, error) {
+	query := r.DB.Model(&model.Stocktake{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var stocktakes []model.Stocktake
+	err := query.Order("created_at desc").Find(&stocktakes).Error
+	return stocktakes, err
+}
+
+func (r *ItemRepository) GetStocktake(id uint) (*model.Stocktake, error) {
+	var st model.Stocktake
+	err := r.DB.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("location_id, item_id").Preload("Item").Preload("Location")
+	}).First(&st, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// lockOpenStocktake читает инвентаризацию под блокировкой и проверяет, что подсчёт ещё идёт
+func lockOpenStocktake(tx *gorm.DB, id uint) (*model.Stocktake, error) {
+	var st model.Stocktake
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&st, id).Error; err != nil {
+		return nil, err
+	}
+	if st.Status != model.StocktakeOpen {
+		return nil, fmt.Errorf("инвентаризация %d уже закрыта", id)
+	}
+	return &st, nil
+}
+
+// stocktakeLine находит строку для подсчёта. Товар, которого не было в снимке,
+// добавляется с текущим остатком в месте как ожидаемым.
+func stocktakeLine(tx *gorm.DB, st *model.Stocktake, count StocktakeCount) (*model.StocktakeLine, error) {
+	var line model.StocktakeLine
+	if count.LineID != 0 {
+		err := tx.Where("id = ? AND stocktake_id = ?", count.LineID, st.ID).First(&line).Error
+		if err != nil {
+			return nil, fmt.Errorf("строка %d не относится к инвентаризации %d", count.LineID, st.ID)
+		}
+		return &line, nil
+	}
+
+	err := tx.Where("stocktake_id = ? AND item_id = ? AND location_id = ?", st.ID, count.ItemID, count.LocationID).
+		Limit(1).Find(&line).Error
+	if err != nil {
+		return nil, err
+	}
+	if line.ID != 0 {
+		return &line, nil
+	}
+
+	if st.LocationID != nil && *st.LocationID != count.LocationID {
+		return nil, fmt.Errorf("место %d не входит в инвентаризацию", count.LocationID)
+	}
+	if err := tx.First(&model.Location{}, count.LocationID).Error; err != nil {
+		return nil, fmt.Errorf("место хранения %d не найдено", count.LocationID)
+	}
+	var item model.Item
+	if err := tx.First(&item, count.ItemID).Error; err != nil {
+		return nil, fmt.Errorf("товар %d не найден", count.ItemID)
+	}
+	if st.Brand != "" && item.Brand != st.Brand {
+		return nil, fmt.Errorf("товар %d не входит в инвентаризацию бренда %s", count.ItemID, st.Brand)
+	}
+
+	var expected []int
+	if err := tx.Model(&model.ItemStock{}).
+		Where("item_id = ? AND location_id = ?", count.ItemID, count.LocationID).
+		Pluck("quantity", &expected).Error; err != nil {
+		return nil, err
+	}
+	line = model.StocktakeLine{
+		StocktakeID: st.ID,
+		ItemID:      count.ItemID,
+		LocationID:  count.LocationID,
+	}
+	if len(expected) > 0 {
+		line.Expected = expected[0]
+	}
+	if err := tx.Create(&line).Error; err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+// RecordCounts записывает подсчитанные количества. Повторный подсчёт строки
+// заменяет прежний.
+func (r *ItemRepository) RecordCounts(id uint, counts []StocktakeCount) ([]model.StocktakeLine, error) {
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("нет подсчитанных позиций")
+	}
+
+	var lines []model.StocktakeLine
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		st, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, count := range counts {
+			if count.Counted < 0 {
+				return fmt.Errorf("количество не может быть отрицательным")
+			}
+			line, err := stocktakeLine(tx, st, count)
+			if err != nil {
+				return err
+			}
+
+			counted := count.Counted
+			line.Counted = &counted
+			line.Variance = counted - line.Expected
+			line.CountedBy = count.UserID
+			line.CountedAt = &now
+			err = tx.Model(line).Updates(map[string]interface{}{
+				"counted":    counted,
+				"variance":   line.Variance,
+				"counted_by": count.UserID,
+				"counted_at": now,
+			}).Error
+			if err != nil {
+				return err
+			}
+			lines = append(lines, *line)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// StocktakeVariance — расхождения по инвентаризации: до утверждения как предпросмотр,
+// после — как итоговый отчёт
+func (r *ItemRepository) StocktakeVariance(id uint) (*StocktakeVariance, error) {
+	st, err := r.GetStocktake(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := StocktakeVariance{Lines: []model.StocktakeLine{}}
+	for _, line := range st.Lines {
+		if line.Counted == nil {
+			report.Uncounted++
+			continue
+		}
+		report.Counted++
+		if line.Variance == 0 {
+			continue
+		}
+
+		cost := 0
+		if line.Item != nil {
+			cost = line.Item.LastCost
+		}
+		if line.Variance > 0 {
+			report.Surplus += line.Variance
+			report.SurplusValue += line.Variance * cost
+		} else {
+			report.Shortage -= line.Variance
+			report.ShortageValue -= line.Variance * cost
+		}
+		report.Lines = append(report.Lines, line)
+	}
+
+	st.Lines = nil
+	report.Stocktake = *st
+	return &report, nil
+}
+
+// stocktakeAdjustment — на сколько поправить остаток места хранения по строке:
+// насчитанное плюс движения после подсчёта минус текущий остаток, но не ниже нуля
+func stocktakeAdjustment(tx *gorm.DB, stocktakeID uint, line model.StocktakeLine) (int, error) {
+	var since int
+	err := tx.Model(&model.StockMovement{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("item_id = ? AND location_id = ? AND created_at > ?", line.ItemID, line.LocationID, line.CountedAt).
+		Where("(stocktake_id IS NULL OR stocktake_id <> ?)", stocktakeID).
+		Scan(&since).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var current []int
+	err = tx.Model(&model.ItemStock{}).
+		Where("item_id = ? AND location_id = ?", line.ItemID, line.LocationID).
+		Pluck("quantity", &current).Error
+	if err != nil {
+		return 0, err
+	}
+	quantity := 0
+	if len(current) > 0 {
+		quantity = current[0]
+	}
+
+	target := max(*line.Counted+since, 0)
+	return target - quantity, nil
+}
+
+// ApproveStocktake проводит подсчёт корректировками. Остаток доводится до того,
+// что насчитали, плюс движения после подсчёта строки — продажи и приходы, прошедшие
+// между подсчётом и утверждением, не теряются, а остаток не уходит в минус.
+func (r *ItemRepository) ApproveStocktake(id uint, reason string, userID *uint) (*StocktakeVariance, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("укажите причину корректировок")
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		st, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		var lines []model.StocktakeLine
+		err = tx.Where("stocktake_id = ? AND counted IS NOT NULL", id).
+			Order("id").
+			Find(&lines).Error
+		if err != nil {
+			return err
+		}
+
+		itemIDs := make([]uint, 0, len(lines))
+		for _, line := range lines {
+			itemIDs = append(itemIDs, line.ItemID)
+		}
+		if err := lockItems(tx, itemIDs); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, line := range lines {
+			adjustment, err := stocktakeAdjustment(tx, st.ID, line)
+			if err != nil {
+				return err
+			}
+			if adjustment == 0 {
+				continue
+			}
+			_, err = applyStockMovement(tx, &model.StockMovement{
+				ItemID:      line.ItemID,
+				Type:        model.MovementAdjustment,
+				Quantity:    adjustment,
+				LocationID:  &line.LocationID,
+				UserID:      userID,
+				Reason:      fmt.Sprintf("инвентаризация %d: %s", id, reason),
+				StocktakeID: &st.ID,
+				CreatedAt:   now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(st).Updates(map[string]interface{}{
+			"status":      model.StocktakeApproved,
+			"reason":      reason,
+			"approved_by": userID,
+			"approved_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.StocktakeVariance(id)
+}
+
+// CancelStocktake закрывает инвентаризацию без изменения остатков
+func (r *ItemRepository) CancelStocktake(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		st, err := lockOpenStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.Model(st).Update("status", model.StocktakeCancelled).Error
+	})
+}
@@ -0,0 +1,314 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// validateTransfer проверяет места и позиции перемещения
+func validateTransfer(tx *gorm.DB, t *model.Transfer) error {
+	if t.FromLocationID == t.ToLocationID {
+		return fmt.Errorf("место отправки и назначения совпадают")
+	}
+	if err := tx.First(&model.Location{}, t.FromLocationID).Error; err != nil {
+		return fmt.Errorf("место отправки %d не найдено", t.FromLocationID)
+	}
+	if err := tx.First(&model.Location{}, t.ToLocationID).Error; err != nil {
+		return fmt.Errorf("место назначения %d не найдено", t.ToLocationID)
+	}
+	if len(t.Lines) == 0 {
+		return fmt.Errorf("перемещение не содержит позиций")
+	}
+	for i := range t.Lines {
+		line := &t.Lines[i]
+		if line.Quantity <= 0 {
+			return fmt.Errorf("позиция %d: количество должно быть больше нуля", i+1)
+		}
+		if err := tx.First(&model.Item{}, line.ItemID).Error; err != nil {
+			return fmt.Errorf("позиция %d: товар %d не найден", i+1, line.ItemID)
+		}
+		line.ID = 0
+		line.Item = nil
+		line.ReceivedQuantity = 0
+		line.Discrepancy = 0
+		line.DiscrepancyNote = ""
+	}
+	return nil
+}
+
+// lockTransfer читает перемещение с позициями под блокировкой
+func lockTransfer(tx *gorm.DB, id uint) (*model.Transfer, error) {
+	var t model.Transfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("transfer_id = ?", id).Order("id").Find(&t.Lines).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func transferItemIDs(t *model.Transfer) []uint {
+	ids := make([]uint, 0, len(t.Lines))
+	for _, line := range t.Lines {
+		ids = append(ids, line.ItemID)
+	}
+	return ids
+}
+
+func (r *ItemRepository) GetTransfer(id uint) (*model.Transfer, error) {
+	var t model.Transfer
+	err := r.DB.Preload("FromLocation.Warehouse").
+		Preload("ToLocation.Warehouse").
+		Preload("Lines.Item").
+		First(&t, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListTransfers — перемещения, новые первыми; status=shipped — всё, что сейчас в пути
+func (r *ItemRepository) ListTransfers(status string) ([]model.Transfer, error) {
+	query := r.DB.Preload("FromLocation.Warehouse").
+		Preload("ToLocation.Warehouse").
+		Preload("Lines.Item")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var transfers []model.Transfer
+	err := query.Order("created_at desc").Find(&transfers).Error
+	return transfers, err
+}
+
+// CreateTransfer создаёт черновик перемещения
+func (r *ItemRepository) CreateTransfer(t *model.Transfer) error {
+	t.ID = 0
+	t.Status = model.TransferDraft
+	t.ShippedAt = nil
+	t.ReceivedAt = nil
+	t.FromLocation = nil
+	t.ToLocation = nil
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := validateTransfer(tx, t); err != nil {
+			return err
+		}
+		return tx.Create(t).Error
+	})
+}
+
+// UpdateTransfer меняет места, заметку и позиции черновика
+func (r *ItemRepository) UpdateTransfer(id uint, update *model.Transfer) (*model.Transfer, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		t, err := lockTransfer(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status != model.TransferDraft {
+			return fmt.Errorf("менять можно только черновик перемещения")
+		}
+		if err := validateTransfer(tx, update); err != nil {
+			return err
+		}
+
+		if err := tx.Where("transfer_id = ?", id).Delete(&model.TransferLine{}).Error; err != nil {
+			return err
+		}
+		for i := range update.Lines {
+			update.Lines[i].TransferID = id
+		}
+		if err := tx.Create(&update.Lines).Error; err != nil {
+			return err
+		}
+		return tx.Model(t).Updates(map[string]interface{}{
+			"from_location_id": update.FromLocationID,
+			"to_location_id":   update.ToLocationID,
+			"notes":            update.Notes,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTransfer(id)
+}
+
+// ShipTransfer отгружает перемещение: товар списывается с места отправки.
+// Не хватает хотя бы одной позиции — не отгружается ничего.
+func (r *ItemRepository) ShipTransfer(id uint, userID *uint) (*model.Transfer, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		t, err := lockTransfer(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status != model.TransferDraft {
+			return fmt.Errorf("отгрузить можно только черновик перемещения")
+		}
+		if err := lockItems(tx, transferItemIDs(t)); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, line := range t.Lines {
+			_, err := applyStockMovement(tx, &model.StockMovement{
+				ItemID:     line.ItemID,
+				Type:       model.MovementTransfer,
+				Quantity:   -line.Quantity,
+				LocationID: &t.FromLocationID,
+				UserID:     userID,
+				Reason:     fmt.Sprintf("отгрузка по перемещению %d", id),
+				TransferID: &t.ID,
+				CreatedAt:  now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(t).Updates(map[string]interface{}{
+			"status":     model.TransferShipped,
+			"shipped_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTransfer(id)
+}
+
+// TransferReceiptLine — сколько принято по позиции перемещения
+type TransferReceiptLine struct {
+	LineID   uint   `json:"lineId"`
+	Quantity int    `json:"quantity"`
+	Note     string `json:"note"` // причина расхождения
+}
+
+// TransferReceipt — приёмка перемещения. Позиции, которых нет в списке,
+// считаются принятыми полностью.
+type TransferReceipt struct {
+	Lines  []TransferReceiptLine `json:"lines"`
+	UserID *uint                 `json:"-"`
+}
+
+// ReceiveTransfer принимает перемещение на месте назначения.
+// Отгруженное приходит на место назначения целиком, а недостача сразу
+// списывается оттуда с пометкой перемещения и записывается в позицию как расхождение.
+func (r *ItemRepository) ReceiveTransfer(id uint, req TransferReceipt) (*model.Transfer, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		t, err := lockTransfer(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status != model.TransferShipped {
+			return fmt.Errorf("принять можно только отгруженное перемещение")
+		}
+		if err := lockItems(tx, transferItemIDs(t)); err != nil {
+			return err
+		}
+
+		received := make(map[uint]TransferReceiptLine, len(req.Lines))
+		for _, rl := range req.Lines {
+			received[rl.LineID] = rl
+		}
+		for lineID := range received {
+			found := false
+			for _, line := range t.Lines {
+				if line.ID == lineID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("позиция %d не относится к перемещению %d", lineID, id)
+			}
+		}
+
+		now := time.Now()
+		for i := range t.Lines {
+			line := &t.Lines[i]
+			line.ReceivedQuantity = line.Quantity
+			if rl, ok := received[line.ID]; ok {
+				if rl.Quantity < 0 || rl.Quantity > line.Quantity {
+					return fmt.Errorf("позиция %d: можно принять от 0 до %d шт.", line.ID, line.Quantity)
+				}
+				line.ReceivedQuantity = rl.Quantity
+				line.DiscrepancyNote = rl.Note
+			}
+			line.Discrepancy = line.Quantity - line.ReceivedQuantity
+
+			_, err := applyStockMovement(tx, &model.StockMovement{
+				ItemID:     line.ItemID,
+				Type:       model.MovementTransfer,
+				Quantity:   line.Quantity,
+				LocationID: &t.ToLocationID,
+				UserID:     req.UserID,
+				Reason:     fmt.Sprintf("приёмка по перемещению %d", id),
+				TransferID: &t.ID,
+				CreatedAt:  now,
+			})
+			if err != nil {
+				return err
+			}
+			if line.Discrepancy > 0 {
+				reason := fmt.Sprintf("недостача по перемещению %d", id)
+				if line.DiscrepancyNote != "" {
+					reason += ": " + line.DiscrepancyNote
+				}
+				_, err := applyStockMovement(tx, &model.StockMovement{
+					ItemID:     line.ItemID,
+					Type:       model.MovementWriteOff,
+					Quantity:   -line.Discrepancy,
+					LocationID: &t.ToLocationID,
+					UserID:     req.UserID,
+					Reason:     reason,
+					TransferID: &t.ID,
+					CreatedAt:  now,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			err = tx.Model(line).Updates(map[string]interface{}{
+				"received_quantity": line.ReceivedQuantity,
+				"discrepancy":       line.Discrepancy,
+				"discrepancy_note":  line.DiscrepancyNote,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(t).Updates(map[string]interface{}{
+			"status":      model.TransferReceived,
+			"received_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTransfer(id)
+}
+
+// CancelTransfer отменяет черновик перемещения
+func (r *ItemRepository) CancelTransfer(id uint) (*model.Transfer, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		t, err := lockTransfer(tx, id)
+		if err != nil {
+			return err
+		}
+		if t.Status != model.TransferDraft {
+			return fmt.Errorf("отменить можно только черновик перемещения")
+		}
+		return tx.Model(t).Update("status", model.TransferCancelled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTransfer(id)
+}
@@ -1,11 +1,16 @@
 package repo
 
 import (
+	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
+	"time"
 	"warehouse-backend/internal/model"
 )
 
+var ErrInvalidInvite = errors.New("invite is invalid, expired or already used")
+
 type UserRepo struct {
 	DB *gorm.DB
 }
@@ -16,7 +21,6 @@ func NewUserRepo(db *gorm.DB) *UserRepo {
 
 func (r *UserRepo) CreateUser(user *model.User) error {
 	log.Println("SAVING TO DB: username:", user.Username)
-	log.Println("SAVING TO DB: password:", user.Password)
 	return r.DB.Create(user).Error
 }
 
@@ -25,3 +29,120 @@ func (r *UserRepo) GetUserByUserName(username string) (*model.User, error) {
 	err := r.DB.Where("username = ?", username).First(&user).Error
 	return &user, err
 }
+
+func (r *UserRepo) GetUserByID(id uint) (*model.User, error) {
+	var user model.User
+	err := r.DB.First(&user, id).Error
+	return &user, err
+}
+
+func (r *UserRepo) ListUsers() ([]model.User, error) {
+	var users []model.User
+	err := r.DB.Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepo) UpdateRole(id uint, role string) error {
+	return r.DB.Model(&model.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+func (r *UserRepo) CountUsers() (int64, error) {
+	var count int64
+	err := r.DB.Model(&model.User{}).Count(&count).Error
+	return count, err
+}
+
+// CreateFirstUser создаёт пользователя, только если таблица users пуста
+func (r *UserRepo) CreateFirstUser(user *model.User) (bool, error) {
+	created := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// блокировка таблицы не даёт двум процессам одновременно создать первого админа
+		if err := tx.Exec("LOCK TABLE users IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.User{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// ErrAdminExists — активный администратор уже есть, повышать никого не нужно
+var ErrAdminExists = errors.New("an active admin already exists")
+
+// PromoteFirstAdmin делает пользователя администратором, только если активного
+// администратора нет (например, после перехода на роли). Пароль не меняется,
+// отключённого пользователя повысить нельзя.
+func (r *UserRepo) PromoteFirstAdmin(username string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// блокировка таблицы не даёт двум процессам одновременно назначить админа
+		if err := tx.Exec("LOCK TABLE users IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		var admins int64
+		err := tx.Model(&model.User{}).
+			Where("role = ? AND NOT disabled", model.RoleAdmin).
+			Count(&admins).Error
+		if err != nil {
+			return err
+		}
+		if admins > 0 {
+			return ErrAdminExists
+		}
+
+		var user model.User
+		if err := tx.Where("username = ?", username).First(&user).Error; err != nil {
+			return err
+		}
+		if user.Disabled {
+			return errors.New("user is disabled")
+		}
+		return tx.Model(&user).Update("role", model.RoleAdmin).Error
+	})
+}
+
+func (r *UserRepo) CreateInvite(invite *model.Invite) error {
+	return r.DB.Create(invite).Error
+}
+
+func (r *UserRepo) ListInvites() ([]model.Invite, error) {
+	var invites []model.Invite
+	err := r.DB.Order("created_at desc").Find(&invites).Error
+	return invites, err
+}
+
+// CreateUserWithInvite погашает приглашение и создаёт пользователя с его ролью
+func (r *UserRepo) CreateUserWithInvite(user *model.User, tokenHash string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var invite model.Invite
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+			First(&invite).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvite
+		}
+		if err != nil {
+			return err
+		}
+
+		user.Role = invite.Role
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&invite).Updates(map[string]interface{}{
+			"used_at":    now,
+			"used_by_id": user.ID,
+		}).Error
+	})
+}
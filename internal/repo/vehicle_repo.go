@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"fmt"
+	"slices"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VehicleRepository struct {
+	DB *gorm.DB
+}
+
+func NewVehicleRepository(db *gorm.DB) *VehicleRepository {
+	return &VehicleRepository{DB: db}
+}
+
+// VehicleFilter — подбор автомобиля по марке, модели, году и двигателю.
+// Пустые поля не фильтруют.
+type VehicleFilter struct {
+	Make   string
+	Model  string
+	Year   int
+	Engine string
+}
+
+func (f VehicleFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Make != "" {
+		query = query.Where("LOWER(vehicles.make) = LOWER(?)", f.Make)
+	}
+	if f.Model != "" {
+		query = query.Where("LOWER(vehicles.model) = LOWER(?)", f.Model)
+	}
+	if f.Engine != "" {
+		query = query.Where("LOWER(vehicles.engine) = LOWER(?)", f.Engine)
+	}
+	if f.Year != 0 {
+		query = query.Where("vehicles.year_from <= ? AND (vehicles.year_to = 0 OR vehicles.year_to >= ?)", f.Year, f.Year)
+	}
+	return query
+}
+
+func validateVehicle(v *model.Vehicle) error {
+	if v.Make == "" || v.Model == "" {
+		return fmt.Errorf("марка и модель обязательны")
+	}
+	if v.YearTo != 0 && v.YearTo < v.YearFrom {
+		return fmt.Errorf("год окончания выпуска раньше года начала")
+	}
+	return nil
+}
+
+func (r *VehicleRepository) CreateVehicle(v *model.Vehicle) error {
+	if err := validateVehicle(v); err != nil {
+		return err
+	}
+	return r.DB.Create(v).Error
+}
+
+func (r *VehicleRepository) UpdateVehicle(id uint, v *model.Vehicle) (*model.Vehicle, error) {
+	var existing model.Vehicle
+	if err := r.DB.First(&existing, id).Error; err != nil {
+		return nil, err
+	}
+	if err := validateVehicle(v); err != nil {
+		return nil, err
+	}
+	v.ID = existing.ID
+	if err := r.DB.Save(v).Error; err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r *VehicleRepository) ListVehicles(f VehicleFilter) ([]model.Vehicle, error) {
+	var vehicles []model.Vehicle
+	err := f.apply(r.DB.Model(&model.Vehicle{})).
+		Order("make, model, year_from").
+		Find(&vehicles).Error
+	return vehicles, err
+}
+
+// AddFitment отмечает, что товар подходит к указанным автомобилям
+func (r *VehicleRepository) AddFitment(itemID uint, vehicleIDs []uint) error {
+	if len(vehicleIDs) == 0 {
+		return fmt.Errorf("не указаны автомобили")
+	}
+
+	var item model.Item
+	if err := r.DB.First(&item, itemID).Error; err != nil {
+		return err
+	}
+
+	// повторы в запросе не должны давать ложное «не найден»
+	vehicleIDs = slices.Clone(vehicleIDs)
+	slices.Sort(vehicleIDs)
+	vehicleIDs = slices.Compact(vehicleIDs)
+
+	var vehicles []model.Vehicle
+	if err := r.DB.Find(&vehicles, vehicleIDs).Error; err != nil {
+		return err
+	}
+	if len(vehicles) != len(vehicleIDs) {
+		return fmt.Errorf("автомобиль не найден")
+	}
+
+	return r.DB.Model(&item).Association("Vehicles").Append(&vehicles)
+}
+
+func (r *VehicleRepository) RemoveFitment(itemID, vehicleID uint) error {
+	item := model.Item{ID: itemID}
+	return r.DB.Model(&item).Association("Vehicles").Delete(&model.Vehicle{ID: vehicleID})
+}
+
+// GetItemVehicles — на какие автомобили подходит товар
+func (r *VehicleRepository) GetItemVehicles(itemID uint) ([]model.Vehicle, error) {
+	var vehicles []model.Vehicle
+	err := r.DB.
+		Joins("JOIN item_fitments ON item_fitments.vehicle_id = vehicles.id").
+		Where("item_fitments.item_id = ?", itemID).
+		Order("make, model, year_from").
+		Find(&vehicles).Error
+	return vehicles, err
+}
+
+// FindItemsForVehicle — запчасти, подходящие к автомобилю по фильтру
+func (r *VehicleRepository) FindItemsForVehicle(f VehicleFilter, inStockOnly bool) ([]model.Item, error) {
+	vehicleIDs := f.apply(r.DB.Model(&model.Vehicle{})).Select("vehicles.id")
+
+	query := withLocations(r.DB.Preload("Images")).
+		Where("items.id IN (?)", r.DB.Table("item_fitments").
+			Select("item_id").
+			Where("vehicle_id IN (?)", vehicleIDs))
+	if inStockOnly {
+		query = query.Where("items.stock > 0")
+	}
+
+	var items []model.Item
+	err := query.Order("items.name").Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,182 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"warehouse-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type WarehouseRepository struct {
+	DB *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) *WarehouseRepository {
+	return &WarehouseRepository{DB: db}
+}
+
+func (r *WarehouseRepository) ListWarehouses() ([]model.Warehouse, error) {
+	var warehouses []model.Warehouse
+	err := r.DB.Preload("Locations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("code")
+	}).Order("id").Find(&warehouses).Error
+	return warehouses, err
+}
+
+func validateLocation(l *model.Location) error {
+	l.Code = strings.TrimSpace(l.Code)
+	if l.Code == "" {
+		return fmt.Errorf("код места хранения обязателен")
+	}
+	return nil
+}
+
+// CreateWarehouse создаёт склад вместе с местами хранения.
+// Если мест нет, создаётся одно основное.
+func (r *WarehouseRepository) CreateWarehouse(w *model.Warehouse) error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return fmt.Errorf("название склада обязательно")
+	}
+	if len(w.Locations) == 0 {
+		w.Locations = []model.Location{{Code: "MAIN", Name: "Основное место", IsDefault: true}}
+	}
+
+	hasDefault := false
+	for i := range w.Locations {
+		loc := &w.Locations[i]
+		if err := validateLocation(loc); err != nil {
+			return err
+		}
+		loc.ID = 0
+		loc.Warehouse = nil
+		if loc.IsDefault {
+			if hasDefault {
+				return fmt.Errorf("основное место может быть только одно")
+			}
+			hasDefault = true
+		}
+	}
+	if !hasDefault {
+		w.Locations[0].IsDefault = true
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if w.IsDefault {
+			if err := tx.Model(&model.Warehouse{}).Where("is_default").Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(w).Error
+	})
+}
+
+// UpdateWarehouse меняет название, адрес и признак основного склада.
+// Снять признак можно только назначив основным другой склад.
+func (r *WarehouseRepository) UpdateWarehouse(id uint, update *model.Warehouse) (*model.Warehouse, error) {
+	var w model.Warehouse
+	if err := r.DB.First(&w, id).Error; err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(update.Name)
+	if name == "" {
+		return nil, fmt.Errorf("название склада обязательно")
+	}
+	if w.IsDefault && !update.IsDefault {
+		return nil, fmt.Errorf("сначала назначьте основным другой склад")
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if update.IsDefault && !w.IsDefault {
+			if err := tx.Model(&model.Warehouse{}).Where("is_default").Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&w).Updates(map[string]interface{}{
+			"name":       name,
+			"address":    update.Address,
+			"is_default": update.IsDefault,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.DB.First(&w, id).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// CreateLocation добавляет место хранения на склад
+func (r *WarehouseRepository) CreateLocation(warehouseID uint, l *model.Location) error {
+	if err := r.DB.First(&model.Warehouse{}, warehouseID).Error; err != nil {
+		return err
+	}
+	if err := validateLocation(l); err != nil {
+		return err
+	}
+	l.ID = 0
+	l.WarehouseID = warehouseID
+	l.Warehouse = nil
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if l.IsDefault {
+			if err := tx.Model(&model.Location{}).
+				Where("warehouse_id = ? AND is_default", warehouseID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(l).Error
+	})
+}
+
+// UpdateLocation меняет код, название и признак основного места
+func (r *WarehouseRepository) UpdateLocation(id uint, update *model.Location) (*model.Location, error) {
+	var l model.Location
+	if err := r.DB.First(&l, id).Error; err != nil {
+		return nil, err
+	}
+	if err := validateLocation(update); err != nil {
+		return nil, err
+	}
+	if l.IsDefault && !update.IsDefault {
+		return nil, fmt.Errorf("сначала назначьте основным другое место")
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if update.IsDefault && !l.IsDefault {
+			if err := tx.Model(&model.Location{}).
+				Where("warehouse_id = ? AND is_default", l.WarehouseID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&l).Updates(map[string]interface{}{
+			"code":       update.Code,
+			"name":       update.Name,
+			"is_default": update.IsDefault,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.DB.First(&l, id).Error; err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// LocationStock — что лежит в месте хранения
+func (r *WarehouseRepository) LocationStock(locationID uint) ([]model.ItemStock, error) {
+	if err := r.DB.First(&model.Location{}, locationID).Error; err != nil {
+		return nil, err
+	}
+	var stocks []model.ItemStock
+	err := r.DB.Preload("Item").
+		Where("location_id = ? AND quantity <> 0", locationID).
+		Order("item_id").
+		Find(&stocks).Error
+	return stocks, err
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+// Форматы выгрузки
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+	ExportPDF  = "pdf"
+)
+
+// ExportWriter пишет таблицу построчно. Close дописывает файл в выходной поток.
+// RowLimit — сколько строк загружать для выгрузки, 0 — все.
+type ExportWriter interface {
+	WriteRow(values []interface{}) error
+	RowLimit() int
+	Close() error
+}
+
+// ExportContentType — MIME-тип файла выгрузки
+func ExportContentType(format string) string {
+	switch format {
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ExportPDF:
+		return "application/pdf"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// NewExportWriter открывает выгрузку в нужном формате и пишет заголовок таблицы
+func NewExportWriter(format string, w io.Writer, title string, header []string) (ExportWriter, error) {
+	var (
+		ew  ExportWriter
+		err error
+	)
+	switch format {
+	case ExportCSV:
+		ew, err = newCSVExport(w)
+	case ExportXLSX:
+		ew, err = newXLSXExport(w)
+	case ExportPDF:
+		ew, err = newPDFExport(w, title, len(header))
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(header))
+	for i, h := range header {
+		values[i] = h
+	}
+	if err := ew.WriteRow(values); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+// csvFlushEvery — через сколько строк сбрасывать CSV клиенту
+const csvFlushEvery = 500
+
+type csvExport struct {
+	w    *csv.Writer
+	rows int
+}
+
+func newCSVExport(w io.Writer) (*csvExport, error) {
+	// BOM, чтобы Excel открыл UTF-8 с кириллицей без настройки кодировки
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	return &csvExport{w: csv.NewWriter(w)}, nil
+}
+
+func (e *csvExport) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = fmt.Sprint(v)
+	}
+	if err := e.w.Write(record); err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%csvFlushEvery == 0 {
+		e.w.Flush()
+		return e.w.Error()
+	}
+	return nil
+}
+
+func (e *csvExport) RowLimit() int { return 0 }
+
+func (e *csvExport) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// xlsxExport пишет через потоковый writer excelize: строки уходят во временный
+// файл, а не держатся в памяти
+type xlsxExport struct {
+	out  io.Writer
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	row  int
+}
+
+func newXLSXExport(w io.Writer) (*xlsxExport, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter(f.GetSheetName(0))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxExport{out: w, file: f, sw: sw}, nil
+}
+
+func (e *xlsxExport) WriteRow(values []interface{}) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.sw.SetRow(cell, values)
+}
+
+func (e *xlsxExport) RowLimit() int { return 0 }
+
+func (e *xlsxExport) Close() error {
+	defer e.file.Close()
+	if err := e.sw.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.out)
+}
+
+// pdfFontPaths — где искать шрифт с кириллицей, если PDF_FONT_PATH не задан
+var pdfFontPaths = []string{
+	"/usr/share/fonts/dejavu/DejaVuSans.ttf",          // alpine: font-dejavu
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf", // debian/ubuntu: fonts-dejavu-core
+}
+
+func pdfFont() (string, error) {
+	if path := os.Getenv("PDF_FONT_PATH"); path != "" {
+		return path, nil
+	}
+	for _, path := range pdfFontPaths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("не найден шрифт для PDF, задайте PDF_FONT_PATH")
+}
+
+// pdfMaxRows — сколько строк попадает в PDF. fpdf собирает документ целиком
+// в памяти, поэтому большие выгрузки обрезаются ещё в запросе — полные данные в CSV и XLSX.
+const pdfMaxRows = 5000
+
+type pdfExport struct {
+	out     io.Writer
+	pdf     *fpdf.Fpdf
+	widths  []float64
+	header  []interface{}
+	started bool
+	rows    int
+}
+
+func newPDFExport(w io.Writer, title string, columns int) (*pdfExport, error) {
+	fontPath, err := pdfFont()
+	if err != nil {
+		return nil, err
+	}
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("main", "", font)
+	pdf.SetFont("main", "", 8)
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	e := &pdfExport{out: w, pdf: pdf}
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	e.widths = make([]float64, columns)
+	for i := range e.widths {
+		e.widths[i] = (pageWidth - left - right) / float64(columns)
+	}
+
+	// заголовок таблицы повторяется на каждой странице
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("main", "", 11)
+		pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+		pdf.SetFont("main", "", 8)
+		if e.header != nil {
+			e.writeCells(e.header, true)
+		}
+	})
+	return e, nil
+}
+
+func (e *pdfExport) writeCells(values []interface{}, fill bool) {
+	e.pdf.SetFillColor(230, 230, 230)
+	for i, v := range values {
+		if i >= len(e.widths) {
+			break
+		}
+		text := e.pdf.SplitText(fmt.Sprint(v), e.widths[i]-1)
+		line := ""
+		if len(text) > 0 {
+			line = text[0] // длинные значения обрезаются до ширины колонки
+		}
+		e.pdf.CellFormat(e.widths[i], 6, line, "1", 0, "L", fill, 0, "")
+	}
+	e.pdf.Ln(-1)
+}
+
+func (e *pdfExport) WriteRow(values []interface{}) error {
+	if !e.started {
+		// первая строка — заголовок таблицы, печатается из SetHeaderFunc
+		e.header = values
+		e.started = true
+		e.pdf.AddPage()
+		return e.pdf.Error()
+	}
+	e.rows++
+	if e.rows > pdfMaxRows {
+		return nil
+	}
+	e.writeCells(values, false)
+	return e.pdf.Error()
+}
+
+// RowLimit — на одну строку больше, чем влезает в PDF: по ней видно, что выгрузка обрезана
+func (e *pdfExport) RowLimit() int { return pdfMaxRows + 1 }
+
+func (e *pdfExport) Close() error {
+	if !e.started {
+		e.pdf.AddPage()
+	}
+	if e.rows > pdfMaxRows {
+		e.pdf.Ln(2)
+		e.pdf.CellFormat(0, 6, fmt.Sprintf("Показаны первые %d строк — полная выгрузка в CSV или XLSX", pdfMaxRows), "", 1, "L", false, 0, "")
+	}
+	return e.pdf.Output(e.out)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"warehouse-backend/internal/repo"
+)
+
+// Поля товара, которые можно загрузить из прайса
+const (
+	FieldPartNumber     = "partNumber"
+	FieldBrand          = "brand"
+	FieldName           = "name"
+	FieldModel          = "model"
+	FieldStock          = "stock"
+	FieldPrice          = "price"
+	FieldWholesalePrice = "wholesalePrice"
+)
+
+// itemColumnAliases — как колонки обычно называются в прайсах поставщиков
+var itemColumnAliases = map[string]string{
+	"partnumber":       FieldPartNumber,
+	"part_number":      FieldPartNumber,
+	"номер":            FieldPartNumber,
+	"артикул":          FieldPartNumber,
+	"каталожный номер": FieldPartNumber,
+	"brand":            FieldBrand,
+	"бренд":            FieldBrand,
+	"производитель":    FieldBrand,
+	"name":             FieldName,
+	"наименование":     FieldName,
+	"название":         FieldName,
+	"model":            FieldModel,
+	"модель":           FieldModel,
+	"stock":            FieldStock,
+	"остаток":          FieldStock,
+	"количество":       FieldStock,
+	"кол-во":           FieldStock,
+	"price":            FieldPrice,
+	"цена":             FieldPrice,
+	"розничная цена":   FieldPrice,
+	"wholesaleprice":   FieldWholesalePrice,
+	"wholesale_price":  FieldWholesalePrice,
+	"опт":              FieldWholesalePrice,
+	"оптовая цена":     FieldWholesalePrice,
+}
+
+// ParseItemSheet превращает таблицу (первая строка — заголовок) в строки импорта.
+// mapping задаёт соответствие «колонка файла → поле товара» поверх стандартных названий.
+func ParseItemSheet(rows [][]string, mapping map[string]string) ([]repo.ItemImportRow, []repo.RowError, error) {
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("пустой файл")
+	}
+
+	fields := make(map[string]int)
+	for i, name := range rows[0] {
+		key := columnKey(name)
+		if field, ok := itemColumnAliases[key]; ok {
+			fields[field] = i
+		}
+	}
+	columns := SheetColumns(rows[0])
+	for column, field := range mapping {
+		if !knownItemField(field) {
+			return nil, nil, fmt.Errorf("неизвестное поле товара %q", field)
+		}
+		i, ok := columns[columnKey(column)]
+		if !ok {
+			return nil, nil, fmt.Errorf("в файле нет колонки %q", column)
+		}
+		fields[field] = i
+	}
+	for _, required := range []string{FieldPartNumber, FieldBrand} {
+		if _, ok := fields[required]; !ok {
+			return nil, nil, fmt.Errorf("не найдена колонка для поля %s", required)
+		}
+	}
+
+	var (
+		result []repo.ItemImportRow
+		errs   []repo.RowError
+	)
+	for n, record := range rows[1:] {
+		rowNum := n + 2 // строки файла с 1, плюс заголовок
+		if isBlankRecord(record) {
+			continue
+		}
+
+		cell := func(field string) (string, bool) {
+			i, ok := fields[field]
+			if !ok {
+				return "", false
+			}
+			if i >= len(record) {
+				return "", true
+			}
+			return strings.TrimSpace(record[i]), true
+		}
+
+		row := repo.ItemImportRow{Row: rowNum}
+		row.PartNumber, _ = cell(FieldPartNumber)
+		row.Brand, _ = cell(FieldBrand)
+		if v, ok := cell(FieldName); ok {
+			row.Name = &v
+		}
+		if v, ok := cell(FieldModel); ok {
+			row.Model = &v
+		}
+
+		var err error
+		for field, dst := range map[string]**int{
+			FieldStock:          &row.Stock,
+			FieldPrice:          &row.Price,
+			FieldWholesalePrice: &row.WholesalePrice,
+		} {
+			v, ok := cell(field)
+			if !ok || v == "" {
+				continue
+			}
+			var n int
+			if n, err = parseSheetNumber(v); err != nil {
+				err = fmt.Errorf("%s: неверное число %q", field, v)
+				break
+			}
+			*dst = &n
+		}
+		if err != nil {
+			errs = append(errs, repo.RowError{Row: rowNum, Error: err.Error()})
+			continue
+		}
+
+		result = append(result, row)
+	}
+	return result, errs, nil
+}
+
+// parseSheetNumber разбирает числа в виде «1 200», «1200,00» или «1200.5» (округляет до целого)
+func parseSheetNumber(s string) (int, error) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(f)), nil
+}
+
+func knownItemField(field string) bool {
+	for _, f := range itemColumnAliases {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
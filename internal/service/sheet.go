@@ -0,0 +1,110 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"warehouse-backend/internal/repo"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ReadSheet читает таблицу из CSV или XLSX (формат по расширению файла).
+// Для XLSX берётся первый лист. Строки CSV, которые не удалось разобрать,
+// возвращаются ошибками по номеру строки, а на их месте в таблице остаётся nil —
+// нумерация остальных строк не сдвигается.
+func ReadSheet(filename string, r io.Reader) ([][]string, []repo.RowError, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return readCSV(r)
+	case ".xlsx":
+		rows, err := readXLSX(r)
+		return rows, nil, err
+	default:
+		return nil, nil, fmt.Errorf("неподдерживаемый формат файла: %s", filepath.Ext(filename))
+	}
+}
+
+func readCSV(r io.Reader) ([][]string, []repo.RowError, error) {
+	reader := newCSVReader(r)
+	var (
+		rows [][]string
+		errs []repo.RowError
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, errs, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// битая строка не мешает остальным
+			rows = append(rows, nil)
+			errs = append(errs, repo.RowError{Row: len(rows), Error: "не удалось разобрать строку: " + parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, record)
+	}
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("в файле нет листов")
+	}
+	return f.GetRows(sheets[0])
+}
+
+// newCSVReader открывает CSV с разделителем «,» или «;» — Excel в русской
+// локали сохраняет через точку с запятой. Разделитель определяется по первой строке.
+func newCSVReader(r io.Reader) *csv.Reader {
+	br := bufio.NewReader(r)
+	first, _ := br.Peek(4096)
+	if i := bytes.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+
+	reader := csv.NewReader(br)
+	if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// SheetColumns сопоставляет названия колонок с их номерами (без учёта регистра, пробелов и BOM)
+func SheetColumns(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[columnKey(name)] = i
+	}
+	return columns
+}
+
+// SheetField возвращает значение колонки или пустую строку
+func SheetField(record []string, columns map[string]int, name string) string {
+	i, ok := columns[columnKey(name)]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func columnKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"golang.org/x/crypto/bcrypt"
 	"log"
+	"time"
 	"warehouse-backend/internal/model"
 	"warehouse-backend/internal/repo"
 
@@ -18,31 +19,237 @@ func NewUserService(r *repo.UserRepo) *UserService {
 	return &UserService{Repo: r}
 }
 
-func (s *UserService) Register(user *model.User) error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", errors.New("password is required")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Register регистрирует пользователя по одноразовому приглашению,
+// роль берётся из приглашения
+func (s *UserService) Register(user *model.User, inviteToken string) error {
+	if inviteToken == "" {
+		return repo.ErrInvalidInvite
+	}
+	hash, err := hashPassword(user.Password)
 	if err != nil {
 		return err
 	}
 	log.Println("REGISTER username:", user.Username)
-	log.Println("REGISTER password hash:", user.Password)
-	user.Password = string(hash)
-	return s.Repo.CreateUser(user)
+	user.Password = hash
+	return s.Repo.CreateUserWithInvite(user, utils.HashToken(inviteToken))
 }
 
-func (s *UserService) Login(username, password string) (string, error) {
+// CreateUser — администратор заводит пользователя сам
+func (s *UserService) CreateUser(user *model.User) error {
+	if !model.ValidRole(user.Role) {
+		return errors.New("unknown role")
+	}
+	hash, err := hashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	if err := s.Repo.CreateUser(user); err != nil {
+		return err
+	}
+	user.Password = ""
+	return nil
+}
+
+// BootstrapAdmin создаёт первого администратора, если пользователей ещё нет.
+// Возвращает false, если в базе уже кто-то есть.
+func (s *UserService) BootstrapAdmin(username, password string) (bool, error) {
+	if username == "" {
+		return false, errors.New("username is required")
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	return s.Repo.CreateFirstUser(&model.User{
+		Username: username,
+		Password: hash,
+		Role:     model.RoleAdmin,
+	})
+}
+
+// PromoteFirstAdmin повышает существующего пользователя до администратора,
+// если активного администратора нет
+func (s *UserService) PromoteFirstAdmin(username string) error {
+	if username == "" {
+		return errors.New("username is required")
+	}
+	return s.Repo.PromoteFirstAdmin(username)
+}
+
+// CreateInvite выпускает приглашение. Токен возвращается один раз —
+// в базе остаётся только его хеш.
+func (s *UserService) CreateInvite(role string, ttl time.Duration, createdBy *uint) (string, *model.Invite, error) {
+	if !model.ValidRole(role) {
+		return "", nil, errors.New("unknown role")
+	}
+	if ttl <= 0 {
+		return "", nil, errors.New("invalid invite lifetime")
+	}
+	token, err := utils.RandomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	invite := &model.Invite{
+		TokenHash:   utils.HashToken(token),
+		Role:        role,
+		ExpiresAt:   time.Now().Add(ttl),
+		CreatedByID: createdBy,
+	}
+	if err := s.Repo.CreateInvite(invite); err != nil {
+		return "", nil, err
+	}
+	return token, invite, nil
+}
+
+func (s *UserService) ListInvites() ([]model.Invite, error) {
+	return s.Repo.ListInvites()
+}
+
+// refreshTokenTTL — сколько живёт сессия без входа по паролю
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// TokenPair — access-токен для запросов и refresh-токен для его обновления
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // срок жизни access-токена в секундах
+}
+
+func (s *UserService) Login(username, password string) (*TokenPair, error) {
 	user, err := s.Repo.GetUserByUserName(username)
 	if err != nil {
-		return "", errors.New("user not found")
+		return nil, errors.New("user not found")
 	}
 
 	log.Println("DEBUG: Username:", username)
-	log.Println("DEBUG: Input password:", password)
-	log.Println("DEBUG: DB hash:", user.Password)
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		log.Println("DEBUG: bcrypt comparison failed:", err) // <== ДОБАВЬ ЭТО
-		return "", errors.New("invalid credentials")
+		log.Println("DEBUG: bcrypt comparison failed:", err)
+		return nil, errors.New("invalid credentials")
+	}
+	if user.Disabled {
+		return nil, errors.New("user is disabled")
+	}
+
+	refresh, token, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	refresh.UserID = user.ID
+	if err := s.Repo.CreateRefreshToken(refresh); err != nil {
+		return nil, err
+	}
+	return issueTokens(user, token)
+}
+
+// Refresh выдаёт новую пару токенов, старый refresh-токен при этом гасится
+func (s *UserService) Refresh(refreshToken string) (*TokenPair, error) {
+	next, token, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.Repo.RotateRefreshToken(utils.HashToken(refreshToken), next)
+	if err != nil {
+		return nil, err
+	}
+	return issueTokens(user, token)
+}
+
+// Logout завершает сессию; с all=true — все сессии пользователя
+func (s *UserService) Logout(refreshToken string, all bool) error {
+	token, err := s.Repo.RevokeRefreshToken(utils.HashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if all {
+		return s.Repo.RevokeUserTokens(token.UserID)
+	}
+	return nil
+}
+
+// ChangePassword меняет пароль и завершает все сессии пользователя
+func (s *UserService) ChangePassword(userID uint, oldPassword, newPassword string) error {
+	user, err := s.Repo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return errors.New("invalid credentials")
+	}
+	hash, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	return s.Repo.UpdatePassword(user.ID, hash)
+}
+
+// SetDisabled отключает пользователя (с отзывом всех сессий) или включает обратно
+func (s *UserService) SetDisabled(id uint, disabled bool) error {
+	if _, err := s.Repo.GetUserByID(id); err != nil {
+		return errors.New("user not found")
+	}
+	return s.Repo.SetDisabled(id, disabled)
+}
+
+func newRefreshToken() (*model.RefreshToken, string, error) {
+	token, err := utils.RandomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	return &model.RefreshToken{
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}, token, nil
+}
+
+func issueTokens(user *model.User, refreshToken string) (*TokenPair, error) {
+	access, err := utils.GenerateJWT(user.ID, user.Username, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		Token:        access,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *UserService) ListUsers() ([]model.User, error) {
+	users, err := s.Repo.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		users[i].Password = ""
+	}
+	return users, nil
+}
+
+func (s *UserService) SetRole(id uint, role string) (*model.User, error) {
+	if !model.ValidRole(role) {
+		return nil, errors.New("unknown role")
+	}
+	user, err := s.Repo.GetUserByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if err := s.Repo.UpdateRole(user.ID, role); err != nil {
+		return nil, err
 	}
-	return utils.GenerateJWT(user.ID, user.Username)
+	user.Role = role
+	user.Password = ""
+	return user, nil
 }
@@ -1,29 +1,226 @@
 package utils
 
 import (
-	"github.com/golang-jwt/jwt/v5"
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("ewfewfefh9h28h4h2hh42fh24fh240fh204h2fhwehfiowehfeiwfheowifhewiofhew")
+// AccessTokenTTL — access-токен живёт недолго, дальше его обновляют через refresh-токен
+const AccessTokenTTL = 15 * time.Minute
+
+// minSecretLength — минимальная длина HMAC-секрета
+const minSecretLength = 32
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   interface{} // nil у ключей, которые только проверяют старые токены
+	verify interface{}
+}
+
+type keySet struct {
+	active *signingKey
+	byKid  map[string]*signingKey
+	algs   []string
+}
+
+var keys *keySet
+
+// LoadJWTKeys читает ключи подписи из окружения:
+//
+//	JWT_ALG              HS256 (по умолчанию), RS256 или EdDSA
+//	JWT_ACTIVE_KID       kid ключа, которым подписываются новые токены
+//	JWT_KEYS             для HS256: "kid1:secret1,kid2:secret2"
+//	JWT_PRIVATE_KEY_FILE для RS256/EdDSA: PEM приватного ключа с kid = JWT_ACTIVE_KID
+//	JWT_PUBLIC_KEYS      для RS256/EdDSA: "kid1:/path/pub1.pem,..." — старые ключи на время ротации
+//
+// Все перечисленные ключи принимаются при проверке, подписывает только активный.
+func LoadJWTKeys() error {
+	alg := os.Getenv("JWT_ALG")
+	if alg == "" {
+		alg = jwt.SigningMethodHS256.Alg()
+	}
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return fmt.Errorf("unsupported JWT_ALG: %s", alg)
+	}
+
+	set := &keySet{byKid: map[string]*signingKey{}, algs: []string{method.Alg()}}
+	activeKid := os.Getenv("JWT_ACTIVE_KID")
+
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		entries, err := parseKeyList(os.Getenv("JWT_KEYS"))
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return errors.New("JWT_KEYS is required")
+		}
+		for _, e := range entries {
+			if len(e.value) < minSecretLength {
+				return fmt.Errorf("JWT key %q is shorter than %d bytes", e.kid, minSecretLength)
+			}
+			secret := []byte(e.value)
+			set.byKid[e.kid] = &signingKey{kid: e.kid, method: method, sign: secret, verify: secret}
+		}
+		if activeKid == "" {
+			activeKid = entries[0].kid
+		}
+
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		if activeKid == "" {
+			return errors.New("JWT_ACTIVE_KID is required for asymmetric keys")
+		}
+		private, public, err := loadPrivateKey(method, os.Getenv("JWT_PRIVATE_KEY_FILE"))
+		if err != nil {
+			return err
+		}
+		set.byKid[activeKid] = &signingKey{kid: activeKid, method: method, sign: private, verify: public}
+
+		entries, err := parseKeyList(os.Getenv("JWT_PUBLIC_KEYS"))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.kid == activeKid {
+				continue
+			}
+			public, err := loadPublicKey(method, e.value)
+			if err != nil {
+				return fmt.Errorf("JWT public key %q: %w", e.kid, err)
+			}
+			set.byKid[e.kid] = &signingKey{kid: e.kid, method: method, verify: public}
+		}
+
+	default:
+		return fmt.Errorf("unsupported JWT_ALG: %s", alg)
+	}
+
+	active, ok := set.byKid[activeKid]
+	if !ok || active.sign == nil {
+		return fmt.Errorf("JWT active key %q not found", activeKid)
+	}
+	set.active = active
+
+	keys = set
+	return nil
+}
+
+type keyEntry struct {
+	kid   string
+	value string
+}
+
+func parseKeyList(raw string) ([]keyEntry, error) {
+	var entries []keyEntry
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kid, value, ok := strings.Cut(part, ":")
+		if !ok || kid == "" || value == "" {
+			return nil, fmt.Errorf("invalid JWT key entry, expected kid:value")
+		}
+		entries = append(entries, keyEntry{kid: kid, value: value})
+	}
+	return entries, nil
+}
+
+func loadPrivateKey(method jwt.SigningMethod, path string) (interface{}, interface{}, error) {
+	if path == "" {
+		return nil, nil, errors.New("JWT_PRIVATE_KEY_FILE is required for asymmetric keys")
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &key.PublicKey, nil
+	default:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, nil, err
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, errors.New("JWT private key is not Ed25519")
+		}
+		return edKey, edKey.Public(), nil
+	}
+}
+
+func loadPublicKey(method jwt.SigningMethod, path string) (crypto.PublicKey, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA:
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	default:
+		return jwt.ParseEdPublicKeyFromPEM(pem)
+	}
+}
+
+func GenerateJWT(userID uint, username, role string) (string, error) {
+	if keys == nil {
+		return "", errors.New("JWT keys are not loaded")
+	}
 
-func GenerateJWT(userID uint, username string) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id":  userID,
 		"username": username,
-		"exp":      time.Now().Add(24 * time.Hour).Unix(),
+		"role":     role,
+		"exp":      time.Now().Add(AccessTokenTTL).Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	token := jwt.NewWithClaims(keys.active.method, claims)
+	token.Header["kid"] = keys.active.kid
+	return token.SignedString(keys.active.sign)
 }
 
 func ValidateToken(tokenString string) (jwt.MapClaims, error) {
+	if keys == nil {
+		return nil, errors.New("JWT keys are not loaded")
+	}
+
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.byKid[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		// алгоритм токена обязан совпадать с алгоритмом ключа
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+		}
+		return key.verify, nil
+	}, jwt.WithValidMethods(keys.algs), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		return claims, nil
 	}
-	return nil, err
+	return nil, errors.New("invalid token")
 }
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+	_ "time/tzdata" // в alpine-образе нет системной базы часовых поясов
+)
+
+// businessLocation — часовой пояс магазина: по нему считаются «сегодня»,
+// границы дат в отчётах и группировка по дням
+var businessLocation = time.UTC
+
+// LoadBusinessLocation читает BUSINESS_TIMEZONE (IANA, например Asia/Almaty).
+// Без настройки используется UTC.
+func LoadBusinessLocation() error {
+	name := os.Getenv("BUSINESS_TIMEZONE")
+	if name == "" {
+		businessLocation = time.UTC
+		return nil
+	}
+	// "Local" — пояс сервера без имени: в SQL (AT TIME ZONE) его не передать
+	if name == "Local" {
+		return fmt.Errorf("invalid BUSINESS_TIMEZONE: use an IANA name like Asia/Almaty instead of Local")
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid BUSINESS_TIMEZONE: %w", err)
+	}
+	businessLocation = loc
+	return nil
+}
+
+// BusinessLocation — часовой пояс магазина
+func BusinessLocation() *time.Location {
+	return businessLocation
+}
+
+// BusinessTimezoneName — имя пояса для SQL (AT TIME ZONE). Имя прошло через
+// time.LoadLocation, поэтому его можно подставлять в запрос как литерал.
+func BusinessTimezoneName() string {
+	return businessLocation.String()
+}
+
+// StartOfDay — полночь того дня, на который приходится t, по времени магазина.
+// В отличие от Truncate(24*time.Hour) учитывает смещение пояса и переход на летнее время.
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(businessLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, businessLocation)
+}
+
+// NextDay — полночь следующего дня по времени магазина (длина дня может быть не 24 часа)
+func NextDay(t time.Time) time.Time {
+	start := StartOfDay(t)
+	return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, businessLocation)
+}
+
+// ParseBusinessDate разбирает дату YYYY-MM-DD как полночь по времени магазина
+func ParseBusinessDate(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", s, businessLocation)
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// withBusinessTimezone переключает пояс магазина на время теста
+func withBusinessTimezone(t *testing.T, name string) {
+	t.Helper()
+	t.Cleanup(func() { _ = LoadBusinessLocation() })
+	t.Setenv("BUSINESS_TIMEZONE", name)
+	if err := LoadBusinessLocation(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestStartOfDayAndNextDay(t *testing.T) {
+	tests := []struct {
+		name      string
+		timezone  string
+		now       time.Time
+		wantStart time.Time
+		wantNext  time.Time
+		wantHours float64
+	}{
+		{
+			name:      "UTC по умолчанию",
+			timezone:  "",
+			now:       utc("2025-01-15T20:30:00Z"),
+			wantStart: utc("2025-01-15T00:00:00Z"),
+			wantNext:  utc("2025-01-16T00:00:00Z"),
+			wantHours: 24,
+		},
+		{
+			name:      "Алматы: по UTC ещё вчера, у магазина уже сегодня",
+			timezone:  "Asia/Almaty",
+			now:       utc("2025-01-15T20:30:00Z"), // 01:30 16 января по Алматы
+			wantStart: utc("2025-01-15T19:00:00Z"),
+			wantNext:  utc("2025-01-16T19:00:00Z"),
+			wantHours: 24,
+		},
+		{
+			name:      "Алматы: последняя минута дня",
+			timezone:  "Asia/Almaty",
+			now:       utc("2025-01-16T18:59:00Z"),
+			wantStart: utc("2025-01-15T19:00:00Z"),
+			wantNext:  utc("2025-01-16T19:00:00Z"),
+			wantHours: 24,
+		},
+		{
+			name:      "Нью-Йорк: переход на летнее время, день 23 часа",
+			timezone:  "America/New_York",
+			now:       utc("2024-03-10T12:00:00Z"),
+			wantStart: utc("2024-03-10T05:00:00Z"),
+			wantNext:  utc("2024-03-11T04:00:00Z"),
+			wantHours: 23,
+		},
+		{
+			name:      "Нью-Йорк: возврат на зимнее время, день 25 часов",
+			timezone:  "America/New_York",
+			now:       utc("2024-11-03T15:00:00Z"),
+			wantStart: utc("2024-11-03T04:00:00Z"),
+			wantNext:  utc("2024-11-04T05:00:00Z"),
+			wantHours: 25,
+		},
+		{
+			name:      "Берлин: переход на летнее время ночью",
+			timezone:  "Europe/Berlin",
+			now:       utc("2024-03-31T00:30:00Z"), // 01:30 CET, до перевода часов
+			wantStart: utc("2024-03-30T23:00:00Z"),
+			wantNext:  utc("2024-03-31T22:00:00Z"),
+			wantHours: 23,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withBusinessTimezone(t, tt.timezone)
+
+			start, next := StartOfDay(tt.now), NextDay(tt.now)
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("StartOfDay = %v, want %v", start.UTC(), tt.wantStart)
+			}
+			if !next.Equal(tt.wantNext) {
+				t.Errorf("NextDay = %v, want %v", next.UTC(), tt.wantNext)
+			}
+			if hours := next.Sub(start).Hours(); hours != tt.wantHours {
+				t.Errorf("длина дня %v ч, want %v", hours, tt.wantHours)
+			}
+			if tt.now.Before(start) || !tt.now.Before(next) {
+				t.Errorf("%v вне окна [%v, %v)", tt.now, start, next)
+			}
+		})
+	}
+}
+
+func TestParseBusinessDate(t *testing.T) {
+	tests := []struct {
+		timezone string
+		date     string
+		want     time.Time
+		wantErr  bool
+	}{
+		{timezone: "", date: "2024-03-10", want: utc("2024-03-10T00:00:00Z")},
+		{timezone: "Asia/Almaty", date: "2025-01-16", want: utc("2025-01-15T19:00:00Z")},
+		{timezone: "America/New_York", date: "2024-03-10", want: utc("2024-03-10T05:00:00Z")},
+		{timezone: "America/New_York", date: "2024-03-11", want: utc("2024-03-11T04:00:00Z")},
+		{timezone: "Europe/Berlin", date: "2024-10-27", want: utc("2024-10-26T22:00:00Z")},
+		{timezone: "Asia/Almaty", date: "16.01.2025", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone+" "+tt.date, func(t *testing.T) {
+			withBusinessTimezone(t, tt.timezone)
+
+			got, err := ParseBusinessDate(tt.date)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ожидали ошибку, получили %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseBusinessDate(%q) = %v, want %v", tt.date, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadBusinessLocation(t *testing.T) {
+	t.Cleanup(func() { _ = LoadBusinessLocation() })
+
+	t.Setenv("BUSINESS_TIMEZONE", "Asia/Almaty")
+	if err := LoadBusinessLocation(); err != nil {
+		t.Fatal(err)
+	}
+	if got := BusinessTimezoneName(); got != "Asia/Almaty" {
+		t.Errorf("BusinessTimezoneName = %q", got)
+	}
+
+	t.Setenv("BUSINESS_TIMEZONE", "Mars/Olympus")
+	if err := LoadBusinessLocation(); err == nil {
+		t.Error("неизвестный пояс должен давать ошибку")
+	}
+
+	t.Setenv("BUSINESS_TIMEZONE", "Local")
+	if err := LoadBusinessLocation(); err == nil {
+		t.Error("Local без имени пояса должен давать ошибку")
+	}
+
+	t.Setenv("BUSINESS_TIMEZONE", "")
+	if err := LoadBusinessLocation(); err != nil {
+		t.Fatal(err)
+	}
+	if BusinessLocation() != time.UTC {
+		t.Errorf("без настройки ожидали UTC, получили %v", BusinessLocation())
+	}
+}